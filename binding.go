@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// songStringFields are the song fields that accept a bare number in place
+// of a string, e.g. a song titled 1979.
+var songStringFields = []string{"group", "song", "text", "link", "cover_url"}
+
+// serverManagedFields are the song fields only the server writes: the
+// UUID, the links, which have their own endpoints, counters, stats derived
+// from the text, results of checks and timestamps. They are dropped from
+// request bodies, so a fetched song can be sent back as is.
+var serverManagedFields = []string{
+	"uuid", "links", "flagged", "link_status", "link_checked_at", "play_count",
+	"verse_count", "word_count", "line_count", "enrichment_pending",
+	"created_at", "updated_at",
+}
+
+// bindSong decodes the JSON body into song like ShouldBindJSON, but first
+// coerces common type mismatches sent by loosely typed clients: a numeric
+// year for release_date, a string id, and numbers in string fields.
+// Server-managed fields in the body are ignored.
+func bindSong(c *gin.Context, song *Song) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	return decodeSong(body, song)
+}
+
+// decodeSong is bindSong for a body that has already been read.
+func decodeSong(body []byte, song *Song) error {
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return &malformedJSONError{offset: int64(len(body))}
+		}
+		return err
+	}
+	for _, name := range serverManagedFields {
+		delete(fields, name)
+	}
+	if err := coerceSongFields(fields); err != nil {
+		return err
+	}
+
+	coerced, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(coerced, song); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(song)
+}
+
+// malformedJSONError reports a body that ended before the JSON value did,
+// which encoding/json returns without an offset.
+type malformedJSONError struct {
+	offset int64
+}
+
+func (e *malformedJSONError) Error() string {
+	return fmt.Sprintf("malformed JSON at offset %d", e.offset)
+}
+
+// respondBindError writes the response for a failed bind, telling a body
+// that is not valid JSON apart from one that fails validation.
+func respondBindError(c *gin.Context, err error) {
+	var malformed *malformedJSONError
+	var syntax *json.SyntaxError
+	switch {
+	case errors.As(err, &malformed):
+		respondError(c, http.StatusBadRequest, codeMalformedJSON, "Malformed JSON at offset "+strconv.FormatInt(malformed.offset, 10))
+	case errors.As(err, &syntax):
+		respondError(c, http.StatusBadRequest, codeMalformedJSON, "Malformed JSON at offset "+strconv.FormatInt(syntax.Offset, 10))
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		respondError(c, http.StatusBadRequest, codeMalformedJSON, "Malformed JSON: unexpected end of input")
+	default:
+		respondError(c, http.StatusBadRequest, codeValidationFailed, "Invalid input: "+err.Error())
+	}
+}
+
+func coerceSongFields(fields map[string]any) error {
+	if _, ok := fields["id"]; ok && cfg.IDType == idTypeUUID {
+		return fmt.Errorf("id can't be used with ID_TYPE=uuid, songs are identified by uuid")
+	}
+	if id, ok := fields["id"].(string); ok {
+		n, err := strconv.ParseUint(id, 10, 0)
+		if err != nil {
+			return fmt.Errorf("id must be a positive integer")
+		}
+		fields["id"] = n
+	}
+
+	if year, ok := fields["release_date"].(json.Number); ok {
+		n, err := strconv.Atoi(year.String())
+		if err != nil || n < 1000 || n > 9999 {
+			return fmt.Errorf("release_date must be a date or a four-digit year")
+		}
+		fields["release_date"] = fmt.Sprintf("%04d-01-01", n)
+	}
+
+	for _, name := range songStringFields {
+		if n, ok := fields[name].(json.Number); ok {
+			fields[name] = n.String()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDecodeSong(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, song Song)
+	}{
+		{
+			name: "numeric year and title",
+			body: `{"group": "Blink-182", "song": 1979, "release_date": 1999}`,
+			check: func(t *testing.T, song Song) {
+				if song.Song != "1979" || song.ReleaseDate != "1999-01-01" {
+					t.Errorf("song = %q, release_date = %q", song.Song, song.ReleaseDate)
+				}
+			},
+		},
+		{
+			name: "string id",
+			body: `{"id": "12", "group": "Muse", "song": "Uprising"}`,
+			check: func(t *testing.T, song Song) {
+				if song.ID != 12 {
+					t.Errorf("id = %d, want 12", song.ID)
+				}
+			},
+		},
+		{
+			name: "server-managed fields are ignored",
+			body: `{"group": "Muse", "song": "Uprising", "play_count": 1000000, "flagged": true,
+				"link_status": "ok", "uuid": "0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00", "verse_count": 9}`,
+			check: func(t *testing.T, song Song) {
+				if song.PlayCount != 0 || song.Flagged || song.LinkStatus != "" || song.UUID != "" || song.VerseCount != 0 {
+					t.Errorf("server-managed fields were bound: %+v", song)
+				}
+			},
+		},
+		{
+			name: "links are ignored",
+			body: `{"group": "Muse", "song": "Uprising", "links": [{"id": 7, "url": "javascript:alert(1)"}]}`,
+			check: func(t *testing.T, song Song) {
+				if len(song.Links) != 0 {
+					t.Errorf("links were bound: %+v", song.Links)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var song Song
+			if err := decodeSong([]byte(tt.body), &song); err != nil {
+				t.Fatalf("decodeSong: %v", err)
+			}
+			tt.check(t, song)
+		})
+	}
+}
+
+func TestDecodeSongRejects(t *testing.T) {
+	for _, body := range []string{
+		`{"song": "Uprising"}`,
+		`{"group": "Muse", "song": "Uprising", "release_date": 99}`,
+		`{"id": "abc", "group": "Muse", "song": "Uprising"}`,
+	} {
+		var song Song
+		if err := decodeSong([]byte(body), &song); err == nil {
+			t.Errorf("decodeSong(%s) succeeded", body)
+		}
+	}
+}
+
+func TestRespondBindError(t *testing.T) {
+	r := gin.New()
+	r.POST("/songs", func(c *gin.Context) {
+		var song Song
+		if err := bindSong(c, &song); err != nil {
+			respondBindError(c, err)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	r.POST("/maintenance", func(c *gin.Context) {
+		var status MaintenanceStatus
+		if err := c.ShouldBindJSON(&status); err != nil {
+			respondBindError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		path     string
+		body     string
+		wantCode string
+		wantMsg  string
+	}{
+		{"/songs", ``, codeMalformedJSON, "Malformed JSON at offset 0"},
+		{"/songs", `{"group": "Queen",`, codeMalformedJSON, "Malformed JSON at offset 18"},
+		{"/songs", `{"group" "Queen"}`, codeMalformedJSON, "Malformed JSON at offset 10"},
+		{"/songs", `{"group": "Queen", "release_date": true}`, codeValidationFailed, "Invalid input"},
+		{"/maintenance", `{"enabled": tru}`, codeMalformedJSON, "Malformed JSON at offset"},
+		{"/maintenance", `{"enabled": true`, codeMalformedJSON, "Malformed JSON"},
+		{"/maintenance", `{}`, codeValidationFailed, "Invalid input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.body, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			var body APIError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode || !strings.HasPrefix(body.Message, tt.wantMsg) {
+				t.Errorf("error = %s %q, want %s %q", body.Code, body.Message, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxRecentLimit = 100
+
+// parseLimit reads the limit of the song lists, 20 unless given and at most
+// maxRecentLimit. Values that aren't a positive number get the default.
+func parseLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	return min(limit, maxRecentLimit)
+}
+
+// @Summary Get recently added songs
+// @Description Get the most recently created songs, newest first
+// @Produce json
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Success 200 {array} Song
+// @Router /songs/recent [get]
+func getRecentSongs(c *gin.Context) {
+	limit := parseLimit(c)
+
+	var songs []Song
+	dbFrom(c).Preload("Links").Order("created_at DESC NULLS LAST").Order("id DESC").Limit(limit).Find(&songs)
+	localizeSongs(c, songs)
+	respondJSON(c, http.StatusOK, songs)
+}
+
+// @Summary Get popular songs
+// @Description Get the most played songs, most plays first
+// @Produce json
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Success 200 {array} Song
+// @Router /songs/popular [get]
+func getPopularSongs(c *gin.Context) {
+	limit := parseLimit(c)
+
+	var songs []Song
+	dbFrom(c).Preload("Links").Where("play_count > 0").Order("play_count DESC").Order("id").Limit(limit).Find(&songs)
+	localizeSongs(c, songs)
+	respondJSON(c, http.StatusOK, songs)
+}
+
+// @Summary Get the longest songs
+// @Description Get the songs with the longest lyrics in characters, longest first
+// @Produce json
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Success 200 {array} Song
+// @Router /songs/longest [get]
+func getLongestSongs(c *gin.Context) {
+	limit := parseLimit(c)
+
+	var songs []Song
+	dbFrom(c).Preload("Links").Where("length(text) > 0").Order("length(text) DESC").Order("id").Limit(limit).Find(&songs)
+	localizeSongs(c, songs)
+	respondJSON(c, http.StatusOK, songs)
+}
+
+const nonLetterBucket = "#"
+
+// indexColumns maps the fields the A-Z index can be built on to their columns.
+var indexColumns = map[string]string{
+	"group": `"group"`,
+	"song":  "song",
+}
+
+type IndexBucket struct {
+	Letter  string   `json:"letter"`
+	Count   int64    `json:"count"`
+	Samples []string `json:"samples,omitempty"`
+}
+
+func indexLetter(first string) string {
+	r, _ := utf8.DecodeRuneInString(first)
+	if !unicode.IsLetter(r) {
+		return nonLetterBucket
+	}
+	return string(unicode.ToUpper(r))
+}
+
+// @Summary Get the A-Z index
+// @Description Get counts of groups or songs by first letter, with everything not starting with a letter under #. For by=group the count is the number of distinct groups.
+// @Produce json
+// @Param by query string false "group (default) or song"
+// @Param samples query int false "Number of sample names per letter"
+// @Success 200 {array} IndexBucket
+// @Router /songs/index [get]
+func getSongIndex(c *gin.Context) {
+	by := c.DefaultQuery("by", "group")
+	column, ok := indexColumns[by]
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "by must be group or song")
+		return
+	}
+	samples, err := strconv.Atoi(c.DefaultQuery("samples", "0"))
+	if err != nil || samples < 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid samples parameter")
+		return
+	}
+	samples = min(samples, maxRecentLimit)
+
+	source := "songs"
+	countExpr := "count(*)"
+	if by == "group" {
+		source = `(SELECT DISTINCT "group" FROM songs) AS groups`
+		countExpr = `count(DISTINCT "group")`
+	}
+	first := fmt.Sprintf("left(trim(%s), 1)", column)
+
+	var rows []struct {
+		First string
+		Count int64
+	}
+	if err := dbFrom(c).Raw(fmt.Sprintf("SELECT upper(%s) AS first, %s AS count FROM %s GROUP BY 1",
+		first, countExpr, source)).Scan(&rows).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to build index")
+		return
+	}
+
+	buckets := map[string]*IndexBucket{}
+	for _, row := range rows {
+		letter := indexLetter(row.First)
+		if buckets[letter] == nil {
+			buckets[letter] = &IndexBucket{Letter: letter}
+		}
+		buckets[letter].Count += row.Count
+	}
+
+	if samples > 0 {
+		var names []struct {
+			First string
+			Value string
+		}
+		if err := dbFrom(c).Raw(fmt.Sprintf(`SELECT first, value FROM (
+			SELECT upper(%s) AS first, %s AS value,
+				row_number() OVER (PARTITION BY upper(%s) ORDER BY %s) AS rn
+			FROM %s) AS ranked WHERE rn <= ? ORDER BY first, value`,
+			first, column, first, column, source), samples).Scan(&names).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to build index")
+			return
+		}
+		for _, name := range names {
+			bucket := buckets[indexLetter(name.First)]
+			if bucket != nil && len(bucket.Samples) < samples {
+				bucket.Samples = append(bucket.Samples, name.Value)
+			}
+		}
+	}
+
+	index := make([]IndexBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		index = append(index, *bucket)
+	}
+	sort.Slice(index, func(i, j int) bool {
+		if (index[i].Letter == nonLetterBucket) != (index[j].Letter == nonLetterBucket) {
+			return index[j].Letter == nonLetterBucket
+		}
+		return index[i].Letter < index[j].Letter
+	})
+	respondJSON(c, http.StatusOK, index)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 20},
+		{"limit=5", 5},
+		{"limit=100", 100},
+		{"limit=101", maxRecentLimit},
+		{"limit=0", 20},
+		{"limit=-3", 20},
+		{"limit=ten", 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs/recent?"+tt.query, nil)
+			if got := parseLimit(c); got != tt.want {
+				t.Errorf("parseLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// fetchSongs serves path with handler and decodes the songs it responds with.
+func fetchSongs(t *testing.T, handler gin.HandlerFunc, path string) []Song {
+	t.Helper()
+	r := gin.New()
+	r.GET("/songs/list", handler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/list"+path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var songs []Song
+	if err := json.Unmarshal(w.Body.Bytes(), &songs); err != nil {
+		t.Fatal(err)
+	}
+	return songs
+}
+
+func songTitles(songs []Song) []string {
+	titles := make([]string, len(songs))
+	for i, song := range songs {
+		titles[i] = song.Song
+	}
+	return titles
+}
+
+func TestGetRecentSongs(t *testing.T) {
+	testDB(t)
+	created := time.Now().Add(-time.Hour)
+	for _, title := range []string{"Oldest", "Middle", "Newest"} {
+		created = created.Add(time.Minute)
+		if err := db.Create(&Song{Group: "Queen", Song: title, CreatedAt: created}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := songTitles(fetchSongs(t, getRecentSongs, "?limit=2"))
+	if want := []string{"Newest", "Middle"}; !slices.Equal(got, want) {
+		t.Errorf("songs = %v, want %v", got, want)
+	}
+}
+
+func TestGetLongestSongs(t *testing.T) {
+	testDB(t)
+	for title, text := range map[string]string{
+		"Short":        "la",
+		"Long":         "la la la la",
+		"Also long":    "lo lo lo lo",
+		"Medium":       "la la la",
+		"Instrumental": "",
+	} {
+		if err := db.Create(&Song{Group: "Queen", Song: title, Text: text}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := songTitles(fetchSongs(t, getLongestSongs, ""))
+	if len(got) != 4 || !slices.Contains(got[:2], "Long") || !slices.Contains(got[:2], "Also long") ||
+		got[2] != "Medium" || got[3] != "Short" {
+		t.Errorf("songs = %v, want the two long songs, then Medium and Short, without Instrumental", got)
+	}
+	if got := songTitles(fetchSongs(t, getLongestSongs, "?limit=1")); len(got) != 1 {
+		t.Errorf("songs with limit=1 = %v, want one", got)
+	}
+}
+
+func TestIndexLetter(t *testing.T) {
+	tests := []struct {
+		first string
+		want  string
+	}{
+		{"q", "Q"},
+		{"Q", "Q"},
+		{"é", "É"},
+		{"ж", "Ж"},
+		{"3", nonLetterBucket},
+		{"!", nonLetterBucket},
+		{"", nonLetterBucket},
+	}
+	for _, tt := range tests {
+		if got := indexLetter(tt.first); got != tt.want {
+			t.Errorf("indexLetter(%q) = %q, want %q", tt.first, got, tt.want)
+		}
+	}
+}
+
+func TestGetSongIndexRejectsInvalidParameters(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/index", getSongIndex)
+	for _, query := range []string{"?by=release_date", "?samples=-1", "?samples=few"} {
+		if code := serve(r, "/songs/index"+query).Code; code != http.StatusBadRequest {
+			t.Errorf("GET /songs/index%s status = %d, want %d", query, code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetSongIndex(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Queen", Song: "Bohemian Rhapsody"},
+		{Group: "queens of the stone age", Song: "No One Knows"},
+		{Group: "10cc", Song: "Dreadlock Holiday"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/songs/index", getSongIndex)
+
+	w := serve(r, "/songs/index?samples=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var index []IndexBucket
+	if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+		t.Fatal(err)
+	}
+	// Distinct groups, letters first and # last.
+	want := []IndexBucket{
+		{Letter: "Q", Count: 2, Samples: []string{"Queen"}},
+		{Letter: nonLetterBucket, Count: 1, Samples: []string{"10cc"}},
+	}
+	if !reflect.DeepEqual(index, want) {
+		t.Errorf("index = %+v, want %+v", index, want)
+	}
+}
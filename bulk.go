@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const invalidFilterIDs = "Invalid filter ids, expected song IDs or UUIDs with ID_TYPE=uuid"
+
+// bulkUpdatableFields maps the fields PATCH /songs may set to the setters
+// that apply them to a song.
+var bulkUpdatableFields = map[string]func(song *Song, value string){
+	"group":        func(song *Song, value string) { song.Group = value },
+	"song":         func(song *Song, value string) { song.Song = value },
+	"release_date": func(song *Song, value string) { song.ReleaseDate = value },
+	"link":         func(song *Song, value string) { song.Link = value },
+	"cover_url":    func(song *Song, value string) { song.CoverURL = value },
+}
+
+type BulkFilter struct {
+	IDs   []SongRef `json:"ids" swaggertype:"array,string"`
+	Group string    `json:"group"`
+	Song  string    `json:"song"`
+}
+
+type BulkUpdateRequest struct {
+	Filter BulkFilter        `json:"filter"`
+	Set    map[string]string `json:"set"`
+}
+
+func (f BulkFilter) empty() bool {
+	return len(f.IDs) == 0 && f.Group == "" && f.Song == ""
+}
+
+// normalize checks the ids of the filter and brings them into the form
+// whereSongRefs expects.
+func (f *BulkFilter) normalize() bool {
+	ids, ok := normalizeSongRefs(f.IDs)
+	f.IDs = ids
+	return ok
+}
+
+func (f BulkFilter) apply(query *gorm.DB) *gorm.DB {
+	if len(f.IDs) > 0 {
+		query = whereSongRefs(query, f.IDs)
+	}
+	if f.Group != "" {
+		query = query.Where(`"group" = ?`, f.Group)
+	}
+	if f.Song != "" {
+		query = query.Where("song = ?", f.Song)
+	}
+	return query
+}
+
+// bulkUpdates checks the requested fields and returns the columns the update
+// writes besides the link, which goes through setPrimaryLink. The values
+// themselves are checked per song by songChecks, like any other update.
+func bulkUpdates(set map[string]string) ([]string, error) {
+	columns := []string{"flagged"}
+	for field, value := range set {
+		if _, ok := bulkUpdatableFields[field]; !ok {
+			return nil, fmt.Errorf("field %q can't be bulk updated", field)
+		}
+		switch field {
+		case "group", "song":
+			if value == "" {
+				return nil, fmt.Errorf("%s can't be empty", field)
+			}
+			columns = append(columns, field, field+"_key")
+		case "link":
+		default:
+			columns = append(columns, field)
+		}
+	}
+	return columns, nil
+}
+
+// applyBulkSet sets the requested fields on song.
+func applyBulkSet(song *Song, set map[string]string) {
+	for field, value := range set {
+		bulkUpdatableFields[field](song, value)
+	}
+}
+
+// @Summary Bulk update songs
+// @Description Set fields on all songs matching a filter. A non-empty filter is required.
+// @Accept json
+// @Produce json
+// @Param request body BulkUpdateRequest true "Filter and field updates"
+// @Success 200 {object} map[string]int64
+// @Failure 409 {object} APIError "Songs were modified during the update, or would get the group and title of another song"
+// @Router /songs [patch]
+func bulkUpdateSongs(c *gin.Context) {
+	var req BulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if req.Filter.empty() {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "A filter is required for bulk updates")
+		return
+	}
+	if !req.Filter.normalize() {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, invalidFilterIDs)
+		return
+	}
+	if len(req.Set) == 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "No fields to update")
+		return
+	}
+	columns, err := bulkUpdates(req.Set)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid input: "+err.Error())
+		return
+	}
+
+	var previous, songs []Song
+	opts := requestCheckOptions(c)
+	err = dbFrom(c).Transaction(func(tx *gorm.DB) error {
+		if err := req.Filter.apply(tx).Find(&previous).Error; err != nil {
+			return err
+		}
+		songs = slices.Clone(previous)
+		for i := range songs {
+			applyBulkSet(&songs[i], req.Set)
+			if err := checkSong(&songs[i], opts); err != nil {
+				return fmt.Errorf("song %s: %w", songRef(songs[i]), err)
+			}
+		}
+		if err := saveRevisions(tx, previous, actorFrom(c), actionBulkUpdate); err != nil {
+			return err
+		}
+		for i := range songs {
+			link := songs[i].Link
+			songs[i].Link = previous[i].Link
+			result := tx.Model(&songs[i]).Where("updated_at = ?", previous[i].UpdatedAt).Select(columns).Updates(&songs[i])
+			if result.Error == nil && result.RowsAffected == 0 {
+				return errSongModified
+			}
+			if result.Error != nil {
+				return result.Error
+			}
+			if err := setPrimaryLink(tx, &songs[i], link); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	var invalid invalidSongError
+	switch {
+	case errors.As(err, &invalid):
+		respondError(c, http.StatusBadRequest, codeInvalidInput, err.Error())
+		return
+	case errors.Is(err, errSongModified):
+		respondError(c, http.StatusConflict, codeConflict, "Songs were modified during the bulk update, please retry")
+		return
+	}
+	if conflict, ok := asConflict(err); ok {
+		respondConflict(c, conflict)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to update songs")
+		return
+	}
+
+	for i, song := range songs {
+		onSongChanged(song, changeTypeFor(previous[i].Group, song.Group))
+	}
+	respondJSON(c, http.StatusOK, gin.H{"updated": len(songs)})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBulkUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{"group", map[string]string{"group": "Queen"}, []string{"flagged", "group", "group_key"}, false},
+		{"song and cover", map[string]string{"song": "Innuendo", "cover_url": ""}, []string{"cover_url", "flagged", "song", "song_key"}, false},
+		{"link goes through the links", map[string]string{"link": "https://example.com"}, []string{"flagged"}, false},
+		{"release date", map[string]string{"release_date": "1991-02-04"}, []string{"flagged", "release_date"}, false},
+		{"empty group", map[string]string{"group": ""}, nil, true},
+		{"empty song", map[string]string{"song": ""}, nil, true},
+		{"not updatable", map[string]string{"text": "la la"}, nil, true},
+		{"server managed", map[string]string{"play_count": "100"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bulkUpdates(tt.set)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bulkUpdates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("bulkUpdates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBulkSet(t *testing.T) {
+	song := Song{Group: "Queen", Song: "Innuendo", Link: "https://old.example"}
+	applyBulkSet(&song, map[string]string{
+		"group":        "Queen + Paul Rodgers",
+		"release_date": "2005-01-01",
+		"link":         "https://new.example",
+		"cover_url":    "https://img.example/cover.jpg",
+	})
+	want := Song{
+		Group:       "Queen + Paul Rodgers",
+		Song:        "Innuendo",
+		ReleaseDate: "2005-01-01",
+		Link:        "https://new.example",
+		CoverURL:    "https://img.example/cover.jpg",
+	}
+	if song.Group != want.Group || song.Song != want.Song || song.ReleaseDate != want.ReleaseDate ||
+		song.Link != want.Link || song.CoverURL != want.CoverURL {
+		t.Errorf("applyBulkSet() = %+v, want %+v", song, want)
+	}
+}
+
+func TestBulkSetGoesThroughSongChecks(t *testing.T) {
+	previous := cfg
+	cfg.MaxNameLength, cfg.MaxTextLength, cfg.Location = 10, 1000, time.UTC
+	cfg.FutureReleaseDates = futureDatesStrict
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		name    string
+		set     map[string]string
+		wantErr bool
+	}{
+		{"valid cover", map[string]string{"cover_url": "https://img.example/cover.jpg"}, false},
+		{"insecure cover", map[string]string{"cover_url": "http://img.example/cover.jpg"}, true},
+		{"group too long", map[string]string{"group": "The Much Too Long Group"}, true},
+		{"past release date", map[string]string{"release_date": "1991-02-04"}, false},
+		{"future release date", map[string]string{"release_date": "2999-01-01"}, true},
+		{"invalid release date", map[string]string{"release_date": "someday"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			song := Song{Group: "Queen", Song: "Innuendo"}
+			applyBulkSet(&song, tt.set)
+			err := checkSong(&song, checkOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSong() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBulkUpdateSongsRejectsBeforeQuerying(t *testing.T) {
+	withIDType(t, idTypeInt)
+	r := gin.New()
+	r.PATCH("/songs", bulkUpdateSongs)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"no filter", `{"set":{"group":"Queen"}}`, "A filter is required for bulk updates"},
+		{"empty filter", `{"filter":{},"set":{"group":"Queen"}}`, "A filter is required for bulk updates"},
+		{"empty filter values", `{"filter":{"ids":[],"group":"","song":""},"set":{"group":"Queen"}}`, "A filter is required for bulk updates"},
+		{"invalid filter ids", `{"filter":{"ids":["1 OR 1=1"]},"set":{"group":"Queen"}}`, invalidFilterIDs},
+		{"nothing to set", `{"filter":{"group":"Queen"}}`, "No fields to update"},
+		{"not updatable", `{"filter":{"group":"Queen"},"set":{"text":"la la"}}`, "can't be bulk updated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/songs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("body = %s, want it to contain %q", w.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBulkEnrich bounds how many songs one POST /songs/enrich may enrich, as
+// it runs within the request.
+const maxBulkEnrich = 25
+
+// Outcomes of enriching a song.
+const (
+	enrichEnriched  = "enriched"
+	enrichUnchanged = "unchanged"
+	enrichFailed    = "failed"
+)
+
+type EnrichResult struct {
+	ID     SongRef  `json:"id" swaggertype:"string"`
+	Status string   `json:"status"`
+	Fields []string `json:"fields,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// reenrichSong fills the empty fields of song from the providers and saves
+// them, unless someone else changed the song in the meantime.
+func reenrichSong(c *gin.Context, song Song) EnrichResult {
+	result := EnrichResult{ID: songRef(song), Status: enrichUnchanged}
+	fields, err := enrichFields(c.Request.Context(), &song, cfg.EnrichmentTimeout)
+	if err == nil && len(fields) > 0 {
+		err = saveEnrichment(dbFrom(c), song, fields, actorFrom(c))
+	}
+
+	var invalid invalidSongError
+	switch {
+	case errors.Is(err, errNoDetail):
+		result.Error = "No details found"
+	case errors.Is(err, errSongModified):
+		result.Error = "Song was modified during enrichment"
+	case errors.As(err, &invalid):
+		result.Error = invalid.Error()
+	case err != nil:
+		result.Error = "Enrichment failed"
+	case len(fields) > 0:
+		result.Status, result.Fields = enrichEnriched, fields
+	}
+	if result.Error != "" {
+		result.Status = enrichFailed
+	}
+	return result
+}
+
+// @Summary Enrich songs
+// @Description Fill the empty release date, text and link of the songs matching a filter from the enrichment providers, reporting the outcome per song
+// @Accept json
+// @Produce json
+// @Param X-Actor header string false "Who is making the change, recorded in the song history"
+// @Param filter body BulkFilter true "Songs to enrich"
+// @Success 200 {array} EnrichResult
+// @Failure 409 {object} APIError
+// @Router /songs/enrich [post]
+func bulkEnrichSongs(c *gin.Context) {
+	if enricher == nil {
+		respondError(c, http.StatusConflict, codeConflict, "No enrichment provider is configured")
+		return
+	}
+	var filter BulkFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if filter.empty() {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "A filter is required")
+		return
+	}
+	if !filter.normalize() {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, invalidFilterIDs)
+		return
+	}
+
+	var songs []Song
+	if err := filter.apply(dbFrom(c)).Order("id").Limit(maxBulkEnrich + 1).Find(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get songs")
+		return
+	}
+	if len(songs) > maxBulkEnrich {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("At most %d songs can be enriched at once", maxBulkEnrich))
+		return
+	}
+
+	// Songs are enriched ENRICHMENT_CONCURRENCY at a time. enrichmentSlots
+	// and enrichmentLimiter bound the provider calls across all requests.
+	results := make([]EnrichResult, len(songs))
+	sem := make(chan struct{}, cfg.EnrichmentConcurrency)
+	var wg sync.WaitGroup
+	for i, song := range songs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = reenrichSong(c, song)
+		}()
+	}
+	wg.Wait()
+	respondJSON(c, http.StatusOK, results)
+}
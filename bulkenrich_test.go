@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func postEnrich(r http.Handler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/songs/enrich", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestBulkEnrichSongsRejectsBeforeQuerying(t *testing.T) {
+	withIDType(t, idTypeInt)
+	r := gin.New()
+	r.POST("/songs/enrich", bulkEnrichSongs)
+
+	withEnricher(t, nil)
+	if w := postEnrich(r, `{"group":"Queen"}`); w.Code != http.StatusConflict {
+		t.Errorf("status without a provider = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	withEnricher(t, stubEnricher{})
+	for _, body := range []string{`{}`, `{"ids":[]}`, `{"ids":["1 OR 1=1"]}`, `{"group":`} {
+		if w := postEnrich(r, body); w.Code != http.StatusBadRequest {
+			t.Errorf("status for %s = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestBulkEnrichSongs(t *testing.T) {
+	testDB(t)
+	withEnricher(t, stubEnricher{detail: SongDetail{Text: "While the sun hangs in the sky"}})
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Queen", Song: "Bicycle Race", ReleaseDate: "1978-10-13", Text: "Bicycle", Link: "https://example.com/bicycle"},
+		{Group: "Yes", Song: "Roundabout"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.POST("/songs/enrich", bulkEnrichSongs)
+
+	w := postEnrich(r, `{"group":"Queen"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var results []EnrichResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 ||
+		results[0].Status != enrichEnriched || len(results[0].Fields) != 1 || results[0].Fields[0] != "text" ||
+		results[1].Status != enrichUnchanged {
+		t.Fatalf("results = %+v, want Innuendo enriched with text and Bicycle Race unchanged", results)
+	}
+	var song Song
+	if err := db.Take(&song, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if song.Text != "While the sun hangs in the sky" {
+		t.Errorf("text = %q, want the enriched text stored", song.Text)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SongCard struct {
+	ID         SongRef `json:"id" swaggertype:"string"`
+	URL        string  `json:"url"`
+	Group      string  `json:"group"`
+	Song       string  `json:"song"`
+	FirstVerse string  `json:"first_verse"`
+	Link       string  `json:"link"`
+	CoverURL   string  `json:"cover_url"`
+}
+
+var songCardTemplate = template.Must(template.New("card").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Song}} - {{.Group}}</title>
+<meta property="og:type" content="music.song">
+<meta property="og:title" content="{{.Song}} - {{.Group}}">
+<meta property="og:description" content="{{.FirstVerse}}">
+<meta property="og:url" content="{{.URL}}">
+{{if .CoverURL}}<meta property="og:image" content="{{.CoverURL}}">
+{{end}}{{if .Link}}<meta property="og:see_also" content="{{.Link}}">
+{{end}}<meta name="twitter:card" content="summary">
+</head>
+<body>
+<h1>{{.Song}}</h1>
+<h2>{{.Group}}</h2>
+<p>{{.FirstVerse}}</p>
+{{if .Link}}<a href="{{.Link}}">Listen</a>{{end}}
+</body>
+</html>
+`))
+
+// songCardURL is the absolute URL of the card page of song, as reached by
+// the client.
+func songCardURL(c *gin.Context, song Song) string {
+	return requestScheme(c) + "://" + c.Request.Host + "/songs/" + url.PathEscape(string(songRef(song))) + "/card"
+}
+
+// @Summary Get a shareable song card
+// @Description Get a compact song summary for embeds, or an HTML page with Open Graph tags when HTML is accepted
+// @Produce json
+// @Produce html
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Success 200 {object} SongCard
+// @Router /songs/{id}/card [get]
+func getSongCard(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+
+	card := SongCard{
+		ID:       songRef(song),
+		URL:      songCardURL(c, song),
+		Group:    song.Group,
+		Song:     song.Song,
+		Link:     song.Link,
+		CoverURL: song.CoverURL,
+	}
+	if verses := splitVerses(song.Text); len(verses) > 0 {
+		card.FirstVerse = verses[0]
+	}
+
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := songCardTemplate.Execute(c.Writer, card); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+	respondJSON(c, http.StatusOK, card)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSongCardURL(t *testing.T) {
+	song := Song{ID: 7, UUID: "0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00"}
+	tests := []struct {
+		idType string
+		target string
+		want   string
+	}{
+		{idTypeInt, "http://music.example/songs/7/card", "http://music.example/songs/7/card"},
+		{idTypeInt, "https://music.example/songs/7/card", "https://music.example/songs/7/card"},
+		{idTypeUUID, "http://music.example:8080/songs/x/card", "http://music.example:8080/songs/0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00/card"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			withIDType(t, tt.idType)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if got := songCardURL(c, song); got != tt.want {
+				t.Errorf("songCardURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings the compression middleware can respond with, in order of
+// preference.
+const (
+	encodingBrotli   = "br"
+	encodingGzip     = "gzip"
+	encodingIdentity = "identity"
+)
+
+var preferredEncodings = []string{encodingBrotli, encodingGzip}
+
+// negotiateEncoding picks the preferred coding the Accept-Encoding header
+// allows, or identity. Codings listed with q=0 are refused, and * stands
+// for any coding not listed.
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding != "" {
+			accepted[coding] = q > 0
+		}
+	}
+	for _, coding := range preferredEncodings {
+		if ok, listed := accepted[coding]; ok || (!listed && accepted["*"]) {
+			return coding
+		}
+	}
+	return encodingIdentity
+}
+
+// compressResponses compresses response bodies with brotli or gzip,
+// whichever the client prefers of those it accepts. It is disabled with
+// COMPRESSION=false.
+func compressResponses(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		addVary(c, "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == encodingIdentity {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = w
+		// Middleware further out, like recovery() after a panic, may still
+		// write once the encoder is closed, so they get the plain writer back.
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// compressWriter encodes the body written through it. It decides on the
+// first write, once the handler has set the headers, so responses that are
+// already encoded or have no body pass through unchanged.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	encoder  io.WriteCloser
+	decided  bool
+}
+
+func (w *compressWriter) start() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	header := w.Header()
+	status := w.Status()
+	// Event streams are left alone, as proxies tend to buffer encoded ones.
+	if header.Get("Content-Encoding") != "" || status == http.StatusNoContent || status == http.StatusNotModified ||
+		strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	if w.encoding == encodingBrotli {
+		w.encoder = brotli.NewWriter(w.ResponseWriter)
+	} else {
+		w.encoder = gzip.NewWriter(w.ResponseWriter)
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.start()
+	if w.encoder == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	return w.encoder.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush pushes what has been encoded so far to the client, for streamed
+// responses.
+func (w *compressWriter) Flush() {
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) close() {
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", encodingIdentity},
+		{"gzip", encodingGzip},
+		{"gzip, br", encodingBrotli},
+		{"br;q=0, gzip", encodingGzip},
+		{"*", encodingBrotli},
+		{"*, br;q=0", encodingGzip},
+		{"gzip;q=0, br;q=0", encodingIdentity},
+		{"deflate", encodingIdentity},
+		{"GZIP", encodingGzip},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func compressRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(recovery(), compressResponses(true))
+	r.GET("/", handler)
+	return r
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var reader io.Reader = w.Body
+	switch w.Header().Get("Content-Encoding") {
+	case encodingBrotli:
+		reader = brotli.NewReader(w.Body)
+	case encodingGzip:
+		gz, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		reader = gz
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	return string(data)
+}
+
+func TestCompressResponsesRoundTrip(t *testing.T) {
+	body := strings.Repeat("la la la ", 100)
+	r := compressRouter(func(c *gin.Context) {
+		localizeSong(c, &Song{})
+		c.String(http.StatusOK, body)
+	})
+	for _, encoding := range []string{encodingBrotli, encodingGzip, encodingIdentity} {
+		t.Run(encoding, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if encoding != encodingIdentity && w.Header().Get("Content-Encoding") != encoding {
+				t.Errorf("Content-Encoding = %q, want %q", w.Header().Get("Content-Encoding"), encoding)
+			}
+			if got := decodeBody(t, w); got != body {
+				t.Errorf("decoded body = %q, want %q", got, body)
+			}
+			if vary := w.Header().Values("Vary"); len(vary) != 2 || vary[0] != "Accept-Encoding" || vary[1] != "Accept-Language" {
+				t.Errorf("Vary = %q, want Accept-Encoding and Accept-Language", vary)
+			}
+		})
+	}
+}
+
+func TestCompressResponsesPanic(t *testing.T) {
+	r := compressRouter(func(c *gin.Context) {
+		panic("boom")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", encodingGzip)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if got := decodeBody(t, w); !strings.Contains(got, codeInternalError) {
+		t.Errorf("body = %q, want the internal error", got)
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // DEFAULT_TIMEZONE shouldn't depend on the host's zoneinfo
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	DatabaseURL           string
+	GormLogLevel          string
+	Port                  string
+	IDType                string
+	PrettyJSON            bool
+	JSONNaming            string
+	SeedOnStart           bool
+	MaintenanceMode       bool
+	Compression           bool
+	AuditAnonymize        bool
+	AuditSalt             string
+	StrictQueryParams     bool
+	RedirectTrailingSlash bool
+	CaseInsensitiveRoutes bool
+	SecurityHeaders       bool
+	FrameOptions          string
+	ContentSecurityPolicy string
+	HSTSMaxAge            int
+	HTTPSRedirect         bool
+	TrustedProxies        []string
+	TrustedProxyPrefixes  []netip.Prefix
+	MaxNameLength         int
+	MaxTextLength         int
+	MaxLimit              int
+	MaxOffset             int
+	MaxRevisions          int
+	MaxVerses             int
+	MaxLyricsBatch        int
+	FuzzyMatchThreshold   float64
+	DuplicateThreshold    float64
+	DefaultLyricsLang     string
+	Features              map[string]bool
+
+	OffsetSunset   time.Time
+	RequestTimeout time.Duration
+	MaxInFlight    int
+	MaxQueued      int
+	GroupsCacheTTL time.Duration
+
+	EnrichmentProviders         []string
+	EnrichmentURL               string
+	EnrichmentAllowedDomains    []string
+	EnrichmentTimeout           time.Duration
+	EnrichmentBackgroundTimeout time.Duration
+	EnrichmentRPS               float64
+	EnrichmentConcurrency       int
+
+	LinkCheckConcurrency int
+	LinkCheckTimeout     time.Duration
+
+	FutureReleaseDates string
+	Timezone           string
+	Location           *time.Location
+
+	ProfanityMode  string
+	ProfanityWords map[string]bool
+}
+
+var cfg Config
+
+// errDatabaseURLMissing is reported when DATABASE_URL is unset or blank,
+// which the driver would otherwise take as a DSN for a local default
+// database and fail to connect to with a confusing error.
+var errDatabaseURLMissing = errors.New("DATABASE_URL is not set; set it in the environment or in .env to a PostgreSQL DSN such as host=localhost user=postgres dbname=music_library port=5432 sslmode=disable")
+
+func loadConfig() Config {
+	timezone := envString("DEFAULT_TIMEZONE", "UTC")
+	// An unknown zone leaves Location nil, which Validate reports.
+	location, _ := time.LoadLocation(timezone)
+	// Invalid entries leave the prefixes empty, which Validate reports.
+	trustedProxies := parseList(os.Getenv("TRUSTED_PROXIES"))
+	trustedProxyPrefixes, _ := parsePrefixes(trustedProxies)
+	return Config{
+		DatabaseURL:           os.Getenv("DATABASE_URL"),
+		GormLogLevel:          strings.ToLower(envString("GORM_LOG_LEVEL", "warn")),
+		Port:                  envString("PORT", "8080"),
+		IDType:                strings.ToLower(envString("ID_TYPE", idTypeInt)),
+		PrettyJSON:            envBool("PRETTY_JSON", false),
+		JSONNaming:            envString("JSON_NAMING", namingSnake),
+		SeedOnStart:           envBool("SEED_ON_START", false),
+		MaintenanceMode:       envBool("MAINTENANCE_MODE", false),
+		Compression:           envBool("COMPRESSION", true),
+		AuditAnonymize:        envBool("AUDIT_ANONYMIZE", false),
+		StrictQueryParams:     envBool("STRICT_QUERY_PARAMS", false),
+		AuditSalt:             os.Getenv("AUDIT_SALT"),
+		RedirectTrailingSlash: envBool("REDIRECT_TRAILING_SLASH", true),
+		CaseInsensitiveRoutes: envBool("CASE_INSENSITIVE_ROUTES", false),
+		SecurityHeaders:       envBool("SECURITY_HEADERS", true),
+		FrameOptions:          strings.ToUpper(envString("FRAME_OPTIONS", frameOptionsDeny)),
+		ContentSecurityPolicy: envString("CONTENT_SECURITY_POLICY", defaultContentSecurityPolicy),
+		HSTSMaxAge:            envInt("HSTS_MAX_AGE_SECONDS", 0),
+		HTTPSRedirect:         envBool("HTTPS_REDIRECT", false),
+		TrustedProxies:        trustedProxies,
+		TrustedProxyPrefixes:  trustedProxyPrefixes,
+		MaxNameLength:         envInt("MAX_NAME_LENGTH", maxNameColumnSize),
+		MaxTextLength:         envInt("MAX_TEXT_LENGTH", 50000),
+		MaxLimit:              envInt("MAX_LIMIT", 100),
+		MaxOffset:             envInt("MAX_OFFSET", 10000),
+		MaxRevisions:          envInt("MAX_REVISIONS", 20),
+		MaxVerses:             envInt("MAX_VERSES", 100),
+		MaxLyricsBatch:        envInt("MAX_LYRICS_BATCH", 100),
+		FuzzyMatchThreshold:   envFloat("FUZZY_MATCH_THRESHOLD", 0.8),
+		DuplicateThreshold:    envFloat("DUPLICATE_THRESHOLD", 0.9),
+		DefaultLyricsLang:     strings.ToLower(envString("DEFAULT_LYRICS_LANG", "und")),
+		Features:              loadFeatures(), // FEATURE_<NAME>=off turns off one of experimentalFeatures
+
+		OffsetSunset:   envDate("OFFSET_SUNSET"),
+		RequestTimeout: time.Duration(envInt("REQUEST_TIMEOUT_MS", 10000)) * time.Millisecond,
+		MaxInFlight:    envInt("MAX_IN_FLIGHT", 0),
+		MaxQueued:      envInt("MAX_QUEUED", 100),
+		GroupsCacheTTL: time.Duration(envInt("GROUPS_CACHE_TTL_SECONDS", 60)) * time.Second,
+
+		EnrichmentProviders:         parseList(os.Getenv("ENRICHMENT_PROVIDER")),
+		EnrichmentURL:               os.Getenv("ENRICHMENT_URL"),
+		EnrichmentAllowedDomains:    parseList(os.Getenv("ENRICHMENT_ALLOWED_DOMAINS")),
+		EnrichmentTimeout:           time.Duration(envInt("ENRICHMENT_TIMEOUT_MS", 3000)) * time.Millisecond,
+		EnrichmentBackgroundTimeout: time.Duration(envInt("ENRICHMENT_BACKGROUND_TIMEOUT_MS", 30000)) * time.Millisecond,
+		EnrichmentRPS:               envFloat("ENRICHMENT_RPS", 0),
+		EnrichmentConcurrency:       envInt("ENRICHMENT_CONCURRENCY", 4),
+
+		LinkCheckConcurrency: envInt("LINK_CHECK_CONCURRENCY", 8),
+		LinkCheckTimeout:     time.Duration(envInt("LINK_CHECK_TIMEOUT_MS", 5000)) * time.Millisecond,
+
+		FutureReleaseDates: strings.ToLower(envString("FUTURE_RELEASE_DATES", futureDatesOff)),
+		Timezone:           timezone,
+		Location:           location,
+
+		ProfanityMode:  strings.ToLower(envString("PROFANITY_MODE", profanityOff)),
+		ProfanityWords: parseWordList(os.Getenv("PROFANITY_WORDS")),
+	}
+}
+
+// Validate checks the configuration and returns every problem found.
+func (c Config) Validate() []error {
+	var errs []error
+	if strings.TrimSpace(c.DatabaseURL) == "" {
+		errs = append(errs, errDatabaseURLMissing)
+	}
+	if c.AuditAnonymize && c.AuditSalt == "" {
+		errs = append(errs, errors.New("AUDIT_SALT is required with AUDIT_ANONYMIZE"))
+	}
+	if c.FrameOptions != frameOptionsDeny && c.FrameOptions != frameOptionsSameOrigin {
+		errs = append(errs, fmt.Errorf("FRAME_OPTIONS must be DENY or SAMEORIGIN, got %q", c.FrameOptions))
+	}
+	if c.HSTSMaxAge < 0 {
+		errs = append(errs, errors.New("HSTS_MAX_AGE_SECONDS must not be negative"))
+	}
+	if _, err := parsePrefixes(c.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must list IP addresses or CIDR ranges: %v", err))
+	}
+	if c.HTTPSRedirect && len(c.TrustedProxies) == 0 {
+		errs = append(errs, errors.New("TRUSTED_PROXIES is required with HTTPS_REDIRECT"))
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", c.Port))
+	}
+	if c.IDType != idTypeInt && c.IDType != idTypeUUID {
+		errs = append(errs, fmt.Errorf("ID_TYPE must be int or uuid, got %q", c.IDType))
+	}
+	if c.JSONNaming != namingSnake && c.JSONNaming != namingCamel {
+		errs = append(errs, fmt.Errorf("JSON_NAMING must be snake_case or camelCase, got %q", c.JSONNaming))
+	}
+	if _, ok := gormLogLevels[c.GormLogLevel]; !ok {
+		errs = append(errs, fmt.Errorf("GORM_LOG_LEVEL must be one of silent, error, warn, info, got %q", c.GormLogLevel))
+	}
+	if c.MaxNameLength < 1 || c.MaxNameLength > maxNameColumnSize {
+		errs = append(errs, fmt.Errorf("MAX_NAME_LENGTH must be between 1 and %d", maxNameColumnSize))
+	}
+	if markerLength := utf8.RuneCountInString(truncatedMarker); c.MaxTextLength <= markerLength {
+		errs = append(errs, fmt.Errorf("MAX_TEXT_LENGTH must be greater than %d", markerLength))
+	}
+	if c.MaxLimit < 1 {
+		errs = append(errs, errors.New("MAX_LIMIT must be at least 1"))
+	}
+	if c.MaxOffset < 0 {
+		errs = append(errs, errors.New("MAX_OFFSET must not be negative"))
+	}
+	if c.MaxRevisions < 1 {
+		errs = append(errs, errors.New("MAX_REVISIONS must be at least 1"))
+	}
+	if c.MaxLyricsBatch < 1 {
+		errs = append(errs, errors.New("MAX_LYRICS_BATCH must be at least 1"))
+	}
+	if c.MaxVerses < 1 {
+		errs = append(errs, errors.New("MAX_VERSES must be at least 1"))
+	}
+	if c.FuzzyMatchThreshold <= 0 || c.FuzzyMatchThreshold > 1 {
+		errs = append(errs, errors.New("FUZZY_MATCH_THRESHOLD must be greater than 0 and at most 1"))
+	}
+	if c.DuplicateThreshold <= 0 || c.DuplicateThreshold > 1 {
+		errs = append(errs, errors.New("DUPLICATE_THRESHOLD must be greater than 0 and at most 1"))
+	}
+	if _, ok := normalizeLang(c.DefaultLyricsLang); !ok {
+		errs = append(errs, fmt.Errorf("DEFAULT_LYRICS_LANG must be a language tag like en or pt-br, got %q", c.DefaultLyricsLang))
+	}
+	if c.RequestTimeout < 0 {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT_MS must not be negative"))
+	}
+	if c.MaxInFlight < 0 {
+		errs = append(errs, errors.New("MAX_IN_FLIGHT must not be negative"))
+	}
+	if c.MaxQueued < 0 {
+		errs = append(errs, errors.New("MAX_QUEUED must not be negative"))
+	}
+	if c.GroupsCacheTTL < 0 {
+		errs = append(errs, errors.New("GROUPS_CACHE_TTL_SECONDS must not be negative"))
+	}
+	for _, name := range c.EnrichmentProviders {
+		if _, ok := enrichers[name]; !ok {
+			errs = append(errs, fmt.Errorf("ENRICHMENT_PROVIDER must list providers out of http, musicbrainz, got %q", name))
+		}
+		if name == "http" && c.EnrichmentURL == "" {
+			errs = append(errs, errors.New("ENRICHMENT_URL is required for the http enrichment provider"))
+		}
+	}
+	if _, ok := parseHTTPURL(c.EnrichmentURL); c.EnrichmentURL != "" && !ok {
+		errs = append(errs, fmt.Errorf("ENRICHMENT_URL must be an http or https URL with a host, got %q", c.EnrichmentURL))
+	}
+	if c.EnrichmentTimeout <= 0 {
+		errs = append(errs, errors.New("ENRICHMENT_TIMEOUT_MS must be positive"))
+	}
+	if c.EnrichmentBackgroundTimeout <= 0 {
+		errs = append(errs, errors.New("ENRICHMENT_BACKGROUND_TIMEOUT_MS must be positive"))
+	}
+	if c.EnrichmentConcurrency < 1 {
+		errs = append(errs, errors.New("ENRICHMENT_CONCURRENCY must be at least 1"))
+	}
+	if c.EnrichmentRPS < 0 {
+		errs = append(errs, errors.New("ENRICHMENT_RPS must not be negative"))
+	}
+	if c.LinkCheckConcurrency < 1 {
+		errs = append(errs, errors.New("LINK_CHECK_CONCURRENCY must be at least 1"))
+	}
+	if c.LinkCheckTimeout <= 0 {
+		errs = append(errs, errors.New("LINK_CHECK_TIMEOUT_MS must be positive"))
+	}
+	switch c.FutureReleaseDates {
+	case futureDatesOff, futureDatesWarn, futureDatesStrict:
+	default:
+		errs = append(errs, fmt.Errorf("FUTURE_RELEASE_DATES must be one of off, warn, strict, got %q", c.FutureReleaseDates))
+	}
+	if c.Location == nil {
+		errs = append(errs, fmt.Errorf("DEFAULT_TIMEZONE must be an IANA time zone like Europe/Berlin, got %q", c.Timezone))
+	}
+	switch c.ProfanityMode {
+	case profanityOff:
+	case profanityFlag, profanityReject:
+		if len(c.ProfanityWords) == 0 {
+			errs = append(errs, fmt.Errorf("PROFANITY_WORDS is required when PROFANITY_MODE is %s", c.ProfanityMode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PROFANITY_MODE must be one of off, flag, reject, got %q", c.ProfanityMode))
+	}
+	return errs
+}
+
+// parseList splits a comma-separated value into its lowercased, trimmed
+// entries.
+func parseList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+func envString(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+func envBool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		logrus.Warnf("Invalid value %q for %s, using default %t", value, key, def)
+		return def
+	}
+	return b
+}
+
+func envFloat(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		logrus.Warnf("Invalid value %q for %s, using default %g", value, key, def)
+		return def
+	}
+	return f
+}
+
+// envDate parses key as a YYYY-MM-DD date, returning the zero time if it is
+// unset or invalid.
+func envDate(key string) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		logrus.Warnf("Invalid value %q for %s, expected YYYY-MM-DD", value, key)
+		return time.Time{}
+	}
+	return t
+}
+
+func envInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		logrus.Warnf("Invalid value %q for %s, using default %d", value, key, def)
+		return def
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateReportsMissingDatabaseURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		unset       bool
+		databaseURL string
+		wantMissing bool
+	}{
+		{"unset", true, "", true},
+		{"empty", false, "", true},
+		{"blank", false, "  \t", true},
+		{"set", false, "host=localhost dbname=music_library", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setenv restores the variable after the test, also when it is
+			// then unset.
+			t.Setenv("DATABASE_URL", tt.databaseURL)
+			if tt.unset {
+				os.Unsetenv("DATABASE_URL")
+			}
+			missing := false
+			for _, err := range loadConfig().Validate() {
+				if errors.Is(err, errDatabaseURLMissing) {
+					missing = true
+				}
+			}
+			if missing != tt.wantMissing {
+				t.Errorf("Validate() reports the missing DATABASE_URL = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("DATABASE_URL", "host=localhost dbname=music_library")
+	if errs := loadConfig().Validate(); len(errs) > 0 {
+		t.Fatalf("Validate() of the defaults = %v, want no errors", errs)
+	}
+
+	tests := []struct {
+		env   string
+		value string
+		want  string
+	}{
+		{"PORT", "http", "PORT must be a number"},
+		{"PORT", "70000", "PORT must be a number"},
+		{"ID_TYPE", "ulid", "ID_TYPE must be int or uuid"},
+		{"MAX_NAME_LENGTH", "0", "MAX_NAME_LENGTH must be between"},
+		{"FRAME_OPTIONS", "ALLOW", "FRAME_OPTIONS must be DENY or SAMEORIGIN"},
+		{"DEFAULT_TIMEZONE", "Mars/Olympus_Mons", "DEFAULT_TIMEZONE must be an IANA time zone"},
+		{"FUTURE_RELEASE_DATES", "sometimes", "FUTURE_RELEASE_DATES must be one of"},
+		{"ENRICHMENT_PROVIDER", "http", "ENRICHMENT_URL is required"},
+		{"TRUSTED_PROXIES", "10.0.0.0/33", "TRUSTED_PROXIES must list IP addresses"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			errs := loadConfig().Validate()
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("Validate() = %v, want one error containing %q", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsUnknownTimezone(t *testing.T) {
+	c := loadConfig()
+	c.Timezone, c.Location = "Mars/Olympus_Mons", nil
+	var found bool
+	for _, err := range c.Validate() {
+		found = found || strings.Contains(err.Error(), "DEFAULT_TIMEZONE")
+	}
+	if !found {
+		t.Error("Validate() didn't report the unknown DEFAULT_TIMEZONE")
+	}
+}
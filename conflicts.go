@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const pgUniqueViolation = "23505"
+
+// uniqueKeyDetail matches the detail Postgres gives for a unique violation,
+// like: Key (uuid)=(...) already exists.
+var uniqueKeyDetail = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// conflictError is a write rejected by a unique index. fields are the
+// columns of the index.
+type conflictError struct {
+	fields []string
+}
+
+func (e conflictError) Error() string {
+	return "Song conflicts with an existing one on " + strings.Join(e.fields, ", ")
+}
+
+// asConflict reports whether err is a unique violation, and on which columns.
+func asConflict(err error) (conflictError, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return conflictError{}, false
+	}
+	match := uniqueKeyDetail.FindStringSubmatch(pgErr.Detail)
+	if match == nil {
+		return conflictError{fields: []string{pgErr.ConstraintName}}, true
+	}
+	var fields []string
+	for _, field := range strings.Split(match[1], ",") {
+		fields = append(fields, strings.Trim(strings.TrimSpace(field), `"`))
+	}
+	return conflictError{fields: fields}, true
+}
+
+func respondConflict(c *gin.Context, conflict conflictError) {
+	respondJSON(c, http.StatusConflict, APIError{Code: codeConflict, Message: conflict.Error(), Fields: conflict.fields})
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestAsConflict(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantFields []string
+		wantOK     bool
+	}{
+		{
+			name:       "single column",
+			err:        &pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (uuid)=(0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00) already exists."},
+			wantFields: []string{"uuid"},
+			wantOK:     true,
+		},
+		{
+			name:       "quoted columns",
+			err:        &pgconn.PgError{Code: pgUniqueViolation, Detail: `Key (song_id, "lang")=(1, en) already exists.`},
+			wantFields: []string{"song_id", "lang"},
+			wantOK:     true,
+		},
+		{
+			name:       "group and title",
+			err:        &pgconn.PgError{Code: pgUniqueViolation, Detail: `Key ("group", song)=(Queen, Innuendo) already exists.`},
+			wantFields: []string{"group", "song"},
+			wantOK:     true,
+		},
+		{
+			name:       "wrapped",
+			err:        fmt.Errorf("create song: %w", &pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (uuid)=(x) already exists."}),
+			wantFields: []string{"uuid"},
+			wantOK:     true,
+		},
+		{
+			name:       "no detail falls back to the constraint",
+			err:        &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "idx_songs_uuid"},
+			wantFields: []string{"idx_songs_uuid"},
+			wantOK:     true,
+		},
+		{
+			name: "other error code",
+			err:  &pgconn.PgError{Code: "23503", Detail: "Key (song_id)=(1) is not present in table \"songs\"."},
+		},
+		{
+			name: "not a database error",
+			err:  errors.New("boom"),
+		},
+		{
+			name: "nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflict, ok := asConflict(tt.err)
+			if ok != tt.wantOK || !reflect.DeepEqual(conflict.fields, tt.wantFields) {
+				t.Errorf("asConflict() = %v, %v, want %v, %v", conflict.fields, ok, tt.wantFields, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRespondConflict(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/songs", nil)
+	respondConflict(c, conflictError{fields: []string{"song_id", "lang"}})
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	var body APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != codeConflict || !reflect.DeepEqual(body.Fields, []string{"song_id", "lang"}) {
+		t.Errorf("body = %+v, want code %s and fields [song_id lang]", body, codeConflict)
+	}
+}
+
+func TestAddSongDuplicateIsConflict(t *testing.T) {
+	testDB(t)
+	r := gin.New()
+	r.POST("/songs", addSong)
+
+	statuses := make([]int, 2)
+	var body APIError
+	for i := range statuses {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(`{"group": "Queen", "song": "Innuendo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		statuses[i] = w.Code
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if statuses[0] != http.StatusCreated || statuses[1] != http.StatusConflict {
+		t.Fatalf("statuses = %v, want [%d %d]", statuses, http.StatusCreated, http.StatusConflict)
+	}
+	if !reflect.DeepEqual(body.Fields, []string{"group", "song"}) {
+		t.Errorf("fields = %v, want [group song]", body.Fields)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+var coverImageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".gif":  true,
+}
+
+// validCoverURL reports whether value is an https URL pointing at an image.
+func validCoverURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return false
+	}
+	return coverImageExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// checkCoverURL validates the optional cover URL of song, rejecting it if it
+// is not an https image URL.
+func checkCoverURL(song *Song, _ checkOptions) error {
+	song.CoverURL = strings.TrimSpace(song.CoverURL)
+	if song.CoverURL != "" && !validCoverURL(song.CoverURL) {
+		return invalidSongError("Invalid cover_url, expected an https image URL")
+	}
+	return nil
+}
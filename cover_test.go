@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestCheckCoverURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"  ", "", false},
+		{"https://img.example/cover.jpg", "https://img.example/cover.jpg", false},
+		{" https://img.example/covers/Innuendo.PNG?size=large ", "https://img.example/covers/Innuendo.PNG?size=large", false},
+		{"https://img.example/cover.webp", "https://img.example/cover.webp", false},
+		{"http://img.example/cover.jpg", "", true},
+		{"https://img.example/cover.svg", "", true},
+		{"https://img.example/cover", "", true},
+		{"https:///cover.jpg", "", true},
+		{"ftp://img.example/cover.jpg", "", true},
+		{"cover.jpg", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			song := Song{CoverURL: tt.url}
+			err := checkCoverURL(&song, checkOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkCoverURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && song.CoverURL != tt.want {
+				t.Errorf("cover_url = %q, want %q", song.CoverURL, tt.want)
+			}
+		})
+	}
+}
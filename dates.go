@@ -0,0 +1,141 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// releaseDateLayout is the canonical format release dates are stored in.
+const releaseDateLayout = "2006-01-02"
+
+// Modes for release dates after today, set with FUTURE_RELEASE_DATES.
+const (
+	futureDatesOff    = "off"
+	futureDatesWarn   = "warn"
+	futureDatesStrict = "strict"
+)
+
+// releaseDateInputLayouts are the formats accepted for incoming release dates.
+var releaseDateInputLayouts = []string{
+	releaseDateLayout,
+	"02.01.2006",
+	time.RFC3339,
+}
+
+// localeDateLayouts maps language tags from Accept-Language to date layouts.
+// Region-specific tags take precedence over the bare language.
+var localeDateLayouts = map[string]string{
+	"en":    "01/02/2006",
+	"en-us": "01/02/2006",
+	"en-gb": "02/01/2006",
+	"de":    "02.01.2006",
+	"ru":    "02.01.2006",
+	"uk":    "02.01.2006",
+	"pl":    "02.01.2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"it":    "02/01/2006",
+	"nl":    "02-01-2006",
+}
+
+// parseReleaseDate parses value in DEFAULT_TIMEZONE. Timestamps with an
+// offset are moved into the zone first, so 2020-01-01T23:30:00-05:00 is
+// January 2nd in Europe/Berlin. A non-empty localLayout, the layout dates are
+// localized to for the client, is accepted as well, so a localized date can be
+// sent back as it was received.
+func parseReleaseDate(value, localLayout string) (time.Time, bool) {
+	layouts := releaseDateInputLayouts
+	if localLayout != "" {
+		layouts = append(slices.Clip(layouts), localLayout)
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), cfg.Location); err == nil {
+			return t.In(cfg.Location), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseUpdatedSince parses an updated_since filter: an RFC 3339 time, or a
+// date meaning its start in DEFAULT_TIMEZONE.
+func parseUpdatedSince(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	t, err := time.ParseInLocation(releaseDateLayout, value, cfg.Location)
+	return t, err == nil
+}
+
+// today returns the current date in DEFAULT_TIMEZONE in storage format.
+func today() string {
+	return time.Now().In(cfg.Location).Format(releaseDateLayout)
+}
+
+// normalizeReleaseDate converts value, in an input layout or localLayout, to
+// the canonical storage format. Empty values are left empty.
+func normalizeReleaseDate(value, localLayout string) (string, bool) {
+	if strings.TrimSpace(value) == "" {
+		return "", true
+	}
+	t, ok := parseReleaseDate(value, localLayout)
+	if !ok {
+		return value, false
+	}
+	return t.Format(releaseDateLayout), true
+}
+
+// dateLayoutFor picks the date layout for the first recognized language in an
+// Accept-Language header, falling back to ISO dates.
+func dateLayoutFor(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if layout, ok := localeDateLayouts[tag]; ok {
+			return layout
+		}
+		lang, _, _ := strings.Cut(tag, "-")
+		if layout, ok := localeDateLayouts[lang]; ok {
+			return layout
+		}
+	}
+	return releaseDateLayout
+}
+
+// localizeSong formats the release date of song according to the request's
+// Accept-Language header. Dates that can't be parsed are left untouched.
+func localizeSong(c *gin.Context, song *Song) {
+	addVary(c, "Accept-Language")
+	layout := dateLayoutFor(c.GetHeader("Accept-Language"))
+	if t, ok := parseReleaseDate(song.ReleaseDate, ""); ok {
+		song.ReleaseDate = t.Format(layout)
+	}
+}
+
+func localizeSongs(c *gin.Context, songs []Song) {
+	for i := range songs {
+		localizeSong(c, &songs[i])
+	}
+}
+
+// checkFutureReleaseDate rejects or warns about a release date after today
+// in DEFAULT_TIMEZONE, depending on cfg.FutureReleaseDates. It expects the date to be
+// normalized already.
+func checkFutureReleaseDate(song *Song, opts checkOptions) error {
+	if cfg.FutureReleaseDates == futureDatesOff || song.ReleaseDate == "" {
+		return nil
+	}
+	if song.ReleaseDate <= today() {
+		return nil
+	}
+	if cfg.FutureReleaseDates == futureDatesStrict {
+		return invalidSongError("release_date must not be in the future")
+	}
+	opts.warn("release_date " + song.ReleaseDate + " is in the future")
+	return nil
+}
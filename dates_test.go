@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLocalizedReleaseDateRoundTrip(t *testing.T) {
+	previous := cfg
+	cfg.Location = time.UTC
+	t.Cleanup(func() { cfg = previous })
+
+	for _, acceptLanguage := range []string{"", "en-US", "en-GB", "de", "fr", "nl", "ja"} {
+		t.Run(acceptLanguage, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs/1", nil)
+			c.Request.Header.Set("Accept-Language", acceptLanguage)
+
+			song := Song{ReleaseDate: "1991-02-04"}
+			localizeSong(c, &song)
+			if err := checkReleaseDate(&song, requestCheckOptions(c)); err != nil {
+				t.Fatalf("checkReleaseDate(%q) error = %v", song.ReleaseDate, err)
+			}
+			if song.ReleaseDate != "1991-02-04" {
+				t.Errorf("release date = %q, want 1991-02-04", song.ReleaseDate)
+			}
+		})
+	}
+}
+
+func TestNormalizeReleaseDate(t *testing.T) {
+	previous := cfg
+	cfg.Location = time.UTC
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		value       string
+		localLayout string
+		want        string
+		wantOK      bool
+	}{
+		{"1991-02-04", "", "1991-02-04", true},
+		{"04.02.1991", "", "1991-02-04", true},
+		{"1991-02-04T23:30:00Z", "", "1991-02-04", true},
+		{"", "", "", true},
+		{"04/02/1991", "", "04/02/1991", false},
+		{"04/02/1991", dateLayoutFor("en-GB"), "1991-02-04", true},
+		{"04/02/1991", dateLayoutFor("en-US"), "1991-04-02", true},
+		{"04-02-1991", dateLayoutFor("nl"), "1991-02-04", true},
+		{"someday", dateLayoutFor("en-US"), "someday", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeReleaseDate(tt.value, tt.localLayout)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("normalizeReleaseDate(%q, %q) = %q, %v, want %q, %v", tt.value, tt.localLayout, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCheckFutureReleaseDate(t *testing.T) {
+	previous := cfg
+	cfg.Location = time.UTC
+	t.Cleanup(func() { cfg = previous })
+	future := time.Now().UTC().AddDate(0, 0, 2).Format(releaseDateLayout)
+
+	tests := []struct {
+		mode        string
+		releaseDate string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{futureDatesOff, future, false, false},
+		{futureDatesWarn, future, false, true},
+		{futureDatesWarn, today(), false, false},
+		{futureDatesStrict, future, true, false},
+		{futureDatesStrict, "1991-02-04", false, false},
+		{futureDatesStrict, "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.releaseDate, func(t *testing.T) {
+			cfg.FutureReleaseDates = tt.mode
+			var warnings []string
+			opts := checkOptions{warn: func(message string) { warnings = append(warnings, message) }}
+			err := checkFutureReleaseDate(&Song{ReleaseDate: tt.releaseDate}, opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkFutureReleaseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (len(warnings) > 0) != tt.wantWarning {
+				t.Errorf("warnings = %q, want a warning: %v", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestParseUpdatedSince(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := cfg
+	cfg.Location = berlin
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		value  string
+		want   time.Time
+		wantOK bool
+	}{
+		{"2024-07-01", time.Date(2024, time.June, 30, 22, 0, 0, 0, time.UTC), true},
+		{"2024-01-01", time.Date(2023, time.December, 31, 23, 0, 0, 0, time.UTC), true},
+		{"2024-07-01T12:00:00-05:00", time.Date(2024, time.July, 1, 17, 0, 0, 0, time.UTC), true},
+		{"", time.Time{}, false},
+		{"yesterday", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseUpdatedSince(tt.value)
+		if ok != tt.wantOK || (ok && !got.Equal(tt.want)) {
+			t.Errorf("parseUpdatedSince(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseReleaseDateInDefaultTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := cfg
+	cfg.Location = berlin
+	t.Cleanup(func() { cfg = previous })
+
+	if got, ok := normalizeReleaseDate("2020-01-01T23:30:00-05:00", ""); !ok || got != "2020-01-02" {
+		t.Errorf("normalizeReleaseDate() = %q, %v, want 2020-01-02 in Europe/Berlin", got, ok)
+	}
+}
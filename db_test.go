@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL, migrates it and
+// empties the song tables. Tests that need PostgreSQL are skipped without it.
+func testDB(t *testing.T) {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	previous := cfg
+	cfg = loadConfig()
+	cfg.DatabaseURL = url
+	t.Cleanup(func() { cfg = previous })
+
+	initDB()
+	if err := db.Exec("TRUNCATE songs, song_links, song_revisions, song_lyrics, song_play_days RESTART IDENTITY CASCADE").Error; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addWarning attaches a warning to the response as an RFC 7234 Warning
+// header. It must be called before the body is written.
+func addWarning(c *gin.Context, message string) {
+	c.Writer.Header().Add("Warning", "299 - "+strconv.Quote(message))
+}
+
+// deprecatedParam marks the query parameter param of a route as deprecated.
+// Requests using it get a Deprecation header, a Sunset header when sunset
+// is set, and message as a warning.
+func deprecatedParam(param, message string, sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.GetQuery(param); ok {
+			c.Header("Deprecation", "true")
+			if !sunset.IsZero() {
+				c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			addWarning(c, message)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeprecatedParam(t *testing.T) {
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name        string
+		sunset      time.Time
+		path        string
+		wantWarning string
+		wantSunset  string
+	}{
+		{"unused", sunset, "/songs?limit=5", "", ""},
+		{"used", sunset, "/songs?offset=10", `299 - "offset is deprecated"`, "Thu, 31 Dec 2026 23:00:00 GMT"},
+		{"empty value", sunset, "/songs?offset=", `299 - "offset is deprecated"`, "Thu, 31 Dec 2026 23:00:00 GMT"},
+		{"no sunset", time.Time{}, "/songs?offset=10", `299 - "offset is deprecated"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/songs", deprecatedParam("offset", "offset is deprecated", tt.sunset), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			w := serve(r, tt.path)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Warning"); got != tt.wantWarning {
+				t.Errorf("Warning = %q, want %q", got, tt.wantWarning)
+			}
+			wantDeprecation := ""
+			if tt.wantWarning != "" {
+				wantDeprecation = "true"
+			}
+			if got := w.Header().Get("Deprecation"); got != wantDeprecation {
+				t.Errorf("Deprecation = %q, want %q", got, wantDeprecation)
+			}
+			if got := w.Header().Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+		})
+	}
+}
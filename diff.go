@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DiffLine struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+const (
+	diffUnchanged = "unchanged"
+	diffAdded     = "added"
+	diffRemoved   = "removed"
+)
+
+// maxDiffCells caps the size of the LCS table, counted in lines of a times
+// lines of b after the common head and tail are taken off.
+const maxDiffCells = 1 << 20
+
+// diffLines returns a line-by-line diff turning a into b, based on the
+// longest common subsequence of the two line sets. Lines shared at the start
+// and end are matched directly; it reports false if what remains between
+// them is too large to diff.
+func diffLines(a, b []string) ([]DiffLine, bool) {
+	var head, tail []DiffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		head = append(head, DiffLine{Type: diffUnchanged, Text: a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		tail = append(tail, DiffLine{Type: diffUnchanged, Text: a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a)*len(b) > maxDiffCells {
+		return nil, false
+	}
+
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := head
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Type: diffUnchanged, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Type: diffRemoved, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Type: diffAdded, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Type: diffRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Type: diffAdded, Text: b[j]})
+	}
+	for k := len(tail) - 1; k >= 0; k-- {
+		diff = append(diff, tail[k])
+	}
+	return diff, true
+}
+
+// @Summary Diff lyrics of two songs
+// @Description Get a line-by-line diff between the text of a song and another song
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param against query string true "ID of the song to compare against"
+// @Success 200 {array} DiffLine
+// @Failure 422 {object} APIError "The texts differ in too many lines to diff"
+// @Router /songs/{id}/diff [get]
+func getSongDiff(c *gin.Context) {
+	againstQuery, ok := whereSongID(dbFrom(c), c.Query("against"))
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid against parameter")
+		return
+	}
+
+	var song, other Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+	if err := againstQuery.First(&other).Error; err != nil {
+		respondError(c, http.StatusNotFound, codeNotFound, "Song not found")
+		return
+	}
+
+	diff, ok := diffLines(strings.Split(song.Text, "\n"), strings.Split(other.Text, "\n"))
+	if !ok {
+		respondError(c, http.StatusUnprocessableEntity, codeInvalidInput, "The texts differ in too many lines to diff")
+		return
+	}
+	respondJSON(c, http.StatusOK, diff)
+}
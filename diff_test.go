@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffLine
+	}{
+		{
+			name: "identical",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{diffUnchanged, "one"}, {diffUnchanged, "two"}},
+		},
+		{
+			name: "line changed in the middle",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []DiffLine{{diffUnchanged, "one"}, {diffRemoved, "two"}, {diffAdded, "2"}, {diffUnchanged, "three"}},
+		},
+		{
+			name: "lines appended",
+			a:    "one",
+			b:    "one\ntwo\nthree",
+			want: []DiffLine{{diffUnchanged, "one"}, {diffAdded, "two"}, {diffAdded, "three"}},
+		},
+		{
+			name: "lines removed",
+			a:    "one\ntwo\nthree",
+			b:    "three",
+			want: []DiffLine{{diffRemoved, "one"}, {diffRemoved, "two"}, {diffUnchanged, "three"}},
+		},
+		{
+			name: "reordered",
+			a:    "a\nb\nc",
+			b:    "c\na\nb",
+			want: []DiffLine{{diffAdded, "c"}, {diffUnchanged, "a"}, {diffUnchanged, "b"}, {diffRemoved, "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := diffLines(strings.Split(tt.a, "\n"), strings.Split(tt.b, "\n"))
+			if !ok {
+				t.Fatal("diffLines reported the input as too large")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesTooLarge(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 2000; i++ {
+		a = append(a, "a")
+		b = append(b, "b")
+	}
+	if _, ok := diffLines(a, b); ok {
+		t.Error("diffLines accepted a 2000x2000 line diff")
+	}
+
+	// A small edit in long texts only diffs the changed lines.
+	a = append(a, "same")
+	b = append(append([]string{}, a...), "extra")
+	if _, ok := diffLines(a, b); !ok {
+		t.Error("diffLines rejected texts sharing all but one line")
+	}
+}
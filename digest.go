@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxDigestDays bounds how far back a digest reaches.
+	maxDigestDays = 365
+	// maxDigestSongs bounds how many songs a digest lists; Total still
+	// counts every song added in the period.
+	maxDigestSongs = 1000
+)
+
+type DigestGroup struct {
+	Group string `json:"group"`
+	Count int    `json:"count"`
+	Songs []Song `json:"songs"`
+}
+
+type Digest struct {
+	Since     time.Time     `json:"since"`
+	Until     time.Time     `json:"until"`
+	Total     int64         `json:"total"`
+	Truncated bool          `json:"truncated"`
+	Groups    []DigestGroup `json:"groups"`
+}
+
+// parsePeriodDays parses a period of days or weeks like 7d or 2w into days,
+// rejecting periods longer than maxDays.
+func parsePeriodDays(value string, maxDays int) (int, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	switch strings.ToLower(value[len(value)-1:]) {
+	case "w":
+		n *= 7
+	case "d":
+	default:
+		return 0, false
+	}
+	return n, n <= maxDays
+}
+
+// @Summary Get a digest of new songs
+// @Description Get the songs added in a recent period grouped by group, groups with the most additions first, for rendering a newsletter
+// @Produce json
+// @Param since query string false "Period to cover in days or weeks, like 7d or 2w (default 7d, max 365d)"
+// @Param Accept-Language header string false "Locale used to format release dates"
+// @Success 200 {object} Digest
+// @Router /digest [get]
+func getDigest(c *gin.Context) {
+	days, ok := parsePeriodDays(c.DefaultQuery("since", "7d"), maxDigestDays)
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("since must be a number of days or weeks like 7d or 2w, at most %dd", maxDigestDays))
+		return
+	}
+	until := time.Now()
+	digest := Digest{Since: until.AddDate(0, 0, -days), Until: until, Groups: []DigestGroup{}}
+
+	query := dbFrom(c).Model(&Song{}).
+		Where("created_at >= ? AND created_at < ?", digest.Since, digest.Until).
+		Session(&gorm.Session{})
+	if err := query.Count(&digest.Total).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get digest")
+		return
+	}
+	var songs []Song
+	if err := query.Preload("Links").Order("created_at DESC").Order("id DESC").Limit(maxDigestSongs).Find(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get digest")
+		return
+	}
+	digest.Truncated = digest.Total > int64(len(songs))
+	localizeSongs(c, songs)
+
+	// Songs stay newest first within their group.
+	index := make(map[string]int)
+	for _, song := range songs {
+		i, ok := index[song.Group]
+		if !ok {
+			i = len(digest.Groups)
+			index[song.Group] = i
+			digest.Groups = append(digest.Groups, DigestGroup{Group: song.Group})
+		}
+		digest.Groups[i].Songs = append(digest.Groups[i].Songs, song)
+		digest.Groups[i].Count++
+	}
+	slices.SortStableFunc(digest.Groups, func(a, b DigestGroup) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Group, b.Group)
+	})
+	respondJSON(c, http.StatusOK, digest)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePeriodDays(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   int
+		wantOK bool
+	}{
+		{"7d", 7, true},
+		{"2w", 14, true},
+		{"3W", 21, true},
+		{"365d", 365, true},
+		{"366d", 366, false},
+		{"53w", 371, false},
+		{"0d", 0, false},
+		{"-1d", 0, false},
+		{"7", 0, false},
+		{"d", 0, false},
+		{"7m", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePeriodDays(tt.value, 365)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parsePeriodDays(%q) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestGetDigestRejectsInvalidPeriod(t *testing.T) {
+	r := gin.New()
+	r.GET("/digest", getDigest)
+	for _, since := range []string{"0d", "1y", "1000w"} {
+		if w := serve(r, "/digest?since="+since); w.Code != http.StatusBadRequest {
+			t.Errorf("status for since=%s = %d, want %d", since, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetDigestGroupsNewSongs(t *testing.T) {
+	testDB(t)
+	old := time.Now().AddDate(0, 0, -10)
+	for _, song := range []Song{
+		{Group: "Yes", Song: "Roundabout"},
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Queen", Song: "Headlong"},
+		{Group: "ABBA", Song: "Waterloo"},
+		{Group: "Queen", Song: "Bicycle Race", CreatedAt: old},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/digest", getDigest)
+
+	w := serve(r, "/digest?since=1w")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var digest Digest
+	if err := json.Unmarshal(w.Body.Bytes(), &digest); err != nil {
+		t.Fatal(err)
+	}
+	if digest.Total != 4 || len(digest.Groups) != 3 {
+		t.Fatalf("digest = %+v, want 4 songs in 3 groups", digest)
+	}
+	queen := digest.Groups[0]
+	if queen.Group != "Queen" || queen.Count != 2 || queen.Songs[0].Song != "Headlong" {
+		t.Errorf("first group = %+v, want Queen with Headlong first", queen)
+	}
+	if digest.Groups[1].Group != "ABBA" || digest.Groups[2].Group != "Yes" {
+		t.Errorf("groups = %+v, want ties ordered by name", digest.Groups)
+	}
+}
@@ -0,0 +1,3319 @@
+// Code generated by swaggo/swag. DO NOT EDIT.
+
+package docs
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/admin/audit": {
+            "get": {
+                "description": "Get the revisions saved when an actor changed songs, newest first. Each revision holds the song as it was before the change. Only updates, bulk updates, reverts and enrichment are recorded, and revisions beyond MAX_REVISIONS per song are pruned.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get changes by an actor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Actor as sent in X-Actor, pseudonymized like stored ones with AUDIT_ANONYMIZE",
+                        "name": "actor",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "update",
+                            "bulk_update",
+                            "revert",
+                            "enrich"
+                        ],
+                        "type": "string",
+                        "description": "Only changes of this kind",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes since this RFC 3339 time or YYYY-MM-DD date",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes before this RFC 3339 time or YYYY-MM-DD date",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.SongRevision"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/check-links": {
+            "post": {
+                "description": "Start checking in the background whether the link of every song still resolves. Results are recorded as link_status and link_checked_at on each song.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Check song links",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/duplicates": {
+            "get": {
+                "description": "Get clusters of songs by the same group with the same or very similar titles, for curators to merge",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Find duplicate songs",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Minimum title similarity from 0 to 1, defaults to DUPLICATE_THRESHOLD",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.DuplicateCluster"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/maintenance": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get maintenance mode",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MaintenanceStatus"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Turn maintenance mode on or off. While it is on, every request that isn't a read gets a 503.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Toggle maintenance mode",
+                "parameters": [
+                    {
+                        "description": "Whether maintenance mode is on",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.MaintenanceStatus"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MaintenanceStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/recompute-lyric-stats": {
+            "post": {
+                "description": "Start recomputing the stored verse_count, word_count and line_count of every song in the background. They are kept up to date on writes; this corrects songs stored before they existed or changed outside the API.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Recompute lyric stats",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/digest": {
+            "get": {
+                "description": "Get the songs added in a recent period grouped by group, groups with the most additions first, for rendering a newsletter",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a digest of new songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period to cover in days or weeks, like 7d or 2w (default 7d, max 365d)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Digest"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "description": "Get the distinct groups in the library with their song counts",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.GroupCount"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/top": {
+            "get": {
+                "description": "Get groups ranked by song count, most songs first. Groups with the same count are ranked by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get top groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.GroupRank"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group}/stats": {
+            "get": {
+                "description": "Get aggregate lyric statistics for a group: songs, verses, average lyric length in characters, release date range and the most common words without stopwords",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get group statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group Name",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of top words (default 10, max 50)",
+                        "name": "words",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.GroupStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group}/years": {
+            "get": {
+                "description": "Get the distinct years a group released songs in, oldest first, with the number of songs per year. Songs without a release date are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the release years of a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group Name",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.YearCount"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lyrics/search": {
+            "get": {
+                "description": "Get every occurrence of a phrase in the lyrics of the catalog, per song with the verse and character offset of each match",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Search lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Phrase",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Songs per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.LyricSearchResult"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "Get the raw API specification generated from the handler annotations",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the OpenAPI specification",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Get list of all songs with optional filtering and pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all songs with filtering and pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group Name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song Name",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated groups to leave out (at most 50)",
+                        "name": "exclude_group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Replace the lyrics of each song with a preview of its first verse",
+                        "name": "preview",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, prefixed with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale whose collation is used to sort group and song",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Exclude songs flagged by the profanity filter",
+                        "name": "safe",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a valid link",
+                        "name": "has_link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a cover",
+                        "name": "has_cover",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) lyrics",
+                        "name": "has_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only songs whose link was last checked as ok, broken or unknown",
+                        "name": "link_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only songs updated since this RFC 3339 time, or since the start of this YYYY-MM-DD date in DEFAULT_TIMEZONE",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the listing if songs changed since this time",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Song"
+                            }
+                        },
+                        "headers": {
+                            "Deprecation": {
+                                "type": "string",
+                                "description": "Set when the deprecated offset parameter is used"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    }
+                }
+            },
+            "put": {
+                "description": "Return the song with the group and title of the body, creating it from the body if there is none yet. Concurrent calls for the same song create it once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a song unless it exists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates, in which they are also accepted",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Song Data",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The song already existed",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "201": {
+                        "description": "The song was created",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "409": {
+                        "description": "A unique field is taken by another song",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new song to the library. If the enrichment providers don't respond within ENRICHMENT_TIMEOUT_MS, the song is created as sent with enrichment_pending set and enriched in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a new song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates, in which they are also accepted",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Song Data",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "409": {
+                        "description": "Another song has the same group and title",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Set fields on all songs matching a filter. A non-empty filter is required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Bulk update songs",
+                "parameters": [
+                    {
+                        "description": "Filter and field updates",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Songs were modified during the update, or would get the group and title of another song",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/echo": {
+            "post": {
+                "description": "Sandbox for integrators: bind, validate and normalize a song exactly like POST /songs and return what would be stored, without storing anything",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Echo a song payload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Song Data",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EchoResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/enrich": {
+            "post": {
+                "description": "Fill the empty release date, text and link of the songs matching a filter from the enrichment providers, reporting the outcome per song",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Enrich songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Who is making the change, recorded in the song history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Songs to enrich",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkFilter"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.EnrichResult"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/exists": {
+            "get": {
+                "description": "Look for a song by group and title, first exactly and then fuzzily",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Check whether a song exists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group Name",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song Name",
+                        "name": "song",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ExistsResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/export.ndjson": {
+            "get": {
+                "description": "Stream every song matching the listing filters as newline-delimited JSON, one song per line, in ID order",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "summary": "Export songs as NDJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by group",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by song",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated groups to leave out (at most 50)",
+                        "name": "exclude_group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Exclude flagged songs",
+                        "name": "safe",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a valid link",
+                        "name": "has_link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a cover",
+                        "name": "has_cover",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only songs whose link was last checked as ok, broken or unknown",
+                        "name": "link_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) lyrics",
+                        "name": "has_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only songs updated since this RFC 3339 time, or since the start of this YYYY-MM-DD date in DEFAULT_TIMEZONE",
+                        "name": "updated_since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One Song per line",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import/jobs": {
+            "post": {
+                "description": "Import songs in the background from a JSON array or NDJSON body, or from the file at ?url=. Songs go through the same checks as POST /songs; rejected ones are listed on the job and skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Start an import job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "http(s) URL of the file to import instead of the body",
+                        "name": "url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting the song",
+                        "name": "truncate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportJob"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import/jobs/{id}": {
+            "get": {
+                "description": "Get the progress of an import job, and its outcome once finished",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get an import job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/incomplete": {
+            "get": {
+                "description": "Get songs lacking any of the given fields, with per-field counts",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get songs missing metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields: release_date, text, link, cover_url",
+                        "name": "missing",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.IncompleteSongs"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/index": {
+            "get": {
+                "description": "Get counts of groups or songs by first letter, with everything not starting with a letter under #. For by=group the count is the number of distinct groups.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the A-Z index",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group (default) or song",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of sample names per letter",
+                        "name": "samples",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.IndexBucket"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/longest": {
+            "get": {
+                "description": "Get the songs with the longest lyrics in characters, longest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the longest songs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Song"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/lookup": {
+            "get": {
+                "description": "Get the song with exactly this group and title. Fails with 409 if more than one song matches.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Look up a song by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group Name",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song Name",
+                        "name": "song",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/lyrics/batch": {
+            "post": {
+                "description": "Get the lyrics of up to MAX_LYRICS_BATCH songs by ID, or UUID with ID_TYPE=uuid, raw and split into verses. Lyrics are keyed by the identifier, and identifiers without a song are listed as missing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get lyrics of several songs",
+                "parameters": [
+                    {
+                        "description": "Song IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LyricsBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.LyricsBatch"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/popular": {
+            "get": {
+                "description": "Get the most played songs, most plays first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get popular songs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Song"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/recent": {
+            "get": {
+                "description": "Get the most recently created songs, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get recently added songs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Song"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/schema": {
+            "get": {
+                "description": "Get the fields of a song with their types and validation rules, for building forms",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the song schema",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.FieldSchema"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/stream": {
+            "get": {
+                "description": "Stream song changes as server-sent events named created, updated, regrouped or deleted, with the song ID and change type as JSON data. Idle streams get a comment every 15 seconds. Clients that fall too far behind are disconnected and should reconnect.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Stream song changes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SongChange"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/suggest": {
+            "get": {
+                "description": "Get songs whose title or group starts with the query, best matches first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Suggest songs for typeahead",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prefix",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 5, max 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Suggestion"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/trending": {
+            "get": {
+                "description": "Get the songs played most within a recent window, most plays first. Plays are counted per day in DEFAULT_TIMEZONE, so the window covers whole days including today.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get trending songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Window in days or weeks, like 7d or 2w (default 7d, max 90d)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.TrendingSong"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Get a song by ID along with lyric statistics",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Related data to embed: links, revisions, translations",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SongWithIncludes"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    }
+                }
+            },
+            "put": {
+                "description": "Update details of an existing song by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale used to format release dates, in which they are also accepted",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated Song Data",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who is making the change, recorded in the song history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is conditional on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "409": {
+                        "description": "Another song has the same group and title",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a song by ID",
+                "summary": "Delete a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/card": {
+            "get": {
+                "description": "Get a compact song summary for embeds, or an HTML page with Open Graph tags when HTML is accepted",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "summary": "Get a shareable song card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SongCard"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/diff": {
+            "get": {
+                "description": "Get a line-by-line diff between the text of a song and another song",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Diff lyrics of two songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the song to compare against",
+                        "name": "against",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.DiffLine"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "The texts differ in too many lines to diff",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/history": {
+            "get": {
+                "description": "Get the stored revisions of a song, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get song history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.SongRevision"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/links": {
+            "post": {
+                "description": "Add a typed link (YouTube, Spotify, ...) to a song. The type is detected from the URL when omitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a link to a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Link",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SongLink"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.SongLink"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/links/{linkID}": {
+            "delete": {
+                "description": "Remove a link by ID from a song. Removing the first link makes the next one the song's link.",
+                "summary": "Remove a link from a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Link ID",
+                        "name": "linkID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyric-similar": {
+            "get": {
+                "description": "Get songs sharing distinctive words with the lyrics of a song, ranked by the summed rarity of the shared words",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get songs with similar lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit (default 10, max 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.SimilarLyrics"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics": {
+            "get": {
+                "description": "Get lyrics of a song with pagination (verses per page). Without pagination at most MAX_VERSES verses are returned and truncated is set if there are more.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get song lyrics with pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verses per page",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return only this verse (1-based) with its neighbors",
+                        "name": "verse",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of the lyrics, defaults to the primary lyrics",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace only the lyrics of a song. The text is normalized like POST /songs/{id}/normalize-lyrics and checked like on a full update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replace song lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New lyrics",
+                        "name": "lyrics",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LyricsUpdate"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who is making the change, recorded in the song history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is conditional on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Replace only the lyrics of a song. The text is normalized like POST /songs/{id}/normalize-lyrics and checked like on a full update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replace song lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New lyrics",
+                        "name": "lyrics",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LyricsUpdate"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who is making the change, recorded in the song history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is conditional on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/full": {
+            "get": {
+                "description": "Get the lyrics exactly as stored together with the verses they parse into",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get raw and parsed lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of the lyrics, defaults to the primary lyrics",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.FullLyrics"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/neighbors": {
+            "get": {
+                "description": "Get the IDs, or UUIDs with ID_TYPE=uuid, of the songs before and after a song in a listing with the given sort order and filters, or null at either end",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get neighboring songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort fields as in the listing, e.g. group,-release_date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale for sorting text fields",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by group",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by song",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated groups to leave out (at most 50)",
+                        "name": "exclude_group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Exclude flagged songs",
+                        "name": "safe",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a valid link",
+                        "name": "has_link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a cover",
+                        "name": "has_cover",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) lyrics",
+                        "name": "has_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only songs whose link was last checked as ok, broken or unknown",
+                        "name": "link_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only songs updated since this RFC 3339 time, or since the start of this YYYY-MM-DD date in DEFAULT_TIMEZONE",
+                        "name": "updated_since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Neighbors"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/normalize-lyrics": {
+            "post": {
+                "description": "Rewrite the stored lyrics with trimmed lines and a single blank line between verses",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Normalize song lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is conditional on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.NormalizeResult"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/play": {
+            "post": {
+                "description": "Increment the play count of a song. Plays aren't edits, so updated_at and the ETag stay the same.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Record a play",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revert/{revisionID}": {
+            "post": {
+                "description": "Restore a song to a stored revision. The current state is kept as a new revision. The restored song goes through the same checks and preconditions as PUT /songs/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Revert a song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID",
+                        "name": "revisionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who is making the change, recorded in the song history",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the revert is conditional on",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "The revision ID is invalid or the revision doesn't pass the current checks",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Another song has the group and title of the revision",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/translations": {
+            "get": {
+                "description": "Get the languages a song has lyrics in, the default language included",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List lyrics languages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add or replace the lyrics of a song in another language. The default language mirrors the song text and can't be set here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a lyrics translation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song ID, or UUID with ID_TYPE=uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Language and lyrics",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.TranslationRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Truncate text exceeding the maximum length instead of rejecting it",
+                        "name": "truncate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.SongLyrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/completeness": {
+            "get": {
+                "description": "Get the percentage of songs with each optional field set, and the overall percentage of optional fields set as a completeness score",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get catalog completeness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Completeness"
+                        }
+                    }
+                }
+            }
+        },
+        "/verses/random": {
+            "get": {
+                "description": "Get a random verse from a random song with lyrics",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a random verse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Seed for deterministic selection",
+                        "name": "seed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RandomVerse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "main.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields names the conflicting fields of a CONFLICT caused by a unique\nindex.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.BatchLyrics": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "verses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.BulkFilter": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.BulkUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "$ref": "#/definitions/main.BulkFilter"
+                },
+                "set": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.Completeness": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "description": "Fields holds the percentage of songs with each optional field set.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "score": {
+                    "description": "Score is the percentage of optional fields set across all songs.",
+                    "type": "number"
+                },
+                "songs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.DiffLine": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.Digest": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.DigestGroup"
+                    }
+                },
+                "since": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "truncated": {
+                    "type": "boolean"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.DigestGroup": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.Song"
+                    }
+                }
+            }
+        },
+        "main.DuplicateCluster": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.EchoResult": {
+            "type": "object",
+            "properties": {
+                "sandbox": {
+                    "type": "boolean"
+                },
+                "song": {
+                    "$ref": "#/definitions/main.SongWithStats"
+                }
+            }
+        },
+        "main.EnrichResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ExistsResult": {
+            "type": "object",
+            "properties": {
+                "exists": {
+                    "type": "boolean"
+                },
+                "match": {
+                    "$ref": "#/definitions/main.Song"
+                },
+                "match_type": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.FieldSchema": {
+            "type": "object",
+            "properties": {
+                "constraints": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "read_only": {
+                    "type": "boolean"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.FullLyrics": {
+            "type": "object",
+            "properties": {
+                "line_count": {
+                    "type": "integer"
+                },
+                "raw": {
+                    "type": "string"
+                },
+                "verse_count": {
+                    "type": "integer"
+                },
+                "verses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.GroupCount": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "songs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.GroupRank": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.GroupStats": {
+            "type": "object",
+            "properties": {
+                "average_text_length": {
+                    "type": "number"
+                },
+                "earliest_release": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "latest_release": {
+                    "type": "string"
+                },
+                "songs": {
+                    "type": "integer"
+                },
+                "top_words": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.WordCount"
+                    }
+                },
+                "verses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ImportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ImportError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.IncompleteSongs": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.Song"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.IndexBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "letter": {
+                    "type": "string"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.LyricMatch": {
+            "type": "object",
+            "properties": {
+                "offset": {
+                    "type": "integer"
+                },
+                "verse": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.LyricSearchResult": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.LyricMatch"
+                    }
+                },
+                "song": {
+                    "type": "string"
+                },
+                "song_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.LyricsBatch": {
+            "type": "object",
+            "properties": {
+                "lyrics": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/main.BatchLyrics"
+                    }
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.LyricsBatchRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.LyricsUpdate": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.MaintenanceStatus": {
+            "type": "object",
+            "required": [
+                "enabled"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.Neighbors": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "previous": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.NormalizeResult": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "verse_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.RandomVerse": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "song_id": {
+                    "type": "string"
+                },
+                "verse": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SimilarLyrics": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "shared_words": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.Song": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "cover_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enrichment_pending": {
+                    "description": "EnrichmentPending is set on a created song whose enrichment timed out\nand is being finished in the background.",
+                    "type": "boolean"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_count": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "link_checked_at": {
+                    "type": "string"
+                },
+                "link_status": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SongLink"
+                    }
+                },
+                "play_count": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "verse_count": {
+                    "type": "integer"
+                },
+                "word_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.SongCard": {
+            "type": "object",
+            "properties": {
+                "cover_url": {
+                    "type": "string"
+                },
+                "first_verse": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SongChange": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SongLink": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "song_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SongLyrics": {
+            "type": "object",
+            "properties": {
+                "lang": {
+                    "type": "string"
+                },
+                "song_id": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SongRevision": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "cover_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "song_id": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SongWithIncludes": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "cover_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enrichment_pending": {
+                    "description": "EnrichmentPending is set on a created song whose enrichment timed out\nand is being finished in the background.",
+                    "type": "boolean"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_count": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "link_checked_at": {
+                    "type": "string"
+                },
+                "link_status": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SongLink"
+                    }
+                },
+                "play_count": {
+                    "type": "integer"
+                },
+                "reading_time_seconds": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SongRevision"
+                    }
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "translations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SongLyrics"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "verse_count": {
+                    "type": "integer"
+                },
+                "word_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.SongWithStats": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "cover_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enrichment_pending": {
+                    "description": "EnrichmentPending is set on a created song whose enrichment timed out\nand is being finished in the background.",
+                    "type": "boolean"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_count": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "link_checked_at": {
+                    "type": "string"
+                },
+                "link_status": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SongLink"
+                    }
+                },
+                "play_count": {
+                    "type": "integer"
+                },
+                "reading_time_seconds": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "verse_count": {
+                    "type": "integer"
+                },
+                "word_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.Suggestion": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.TranslationRequest": {
+            "type": "object",
+            "required": [
+                "lang",
+                "text"
+            ],
+            "properties": {
+                "lang": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.TrendingSong": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "cover_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enrichment_pending": {
+                    "description": "EnrichmentPending is set on a created song whose enrichment timed out\nand is being finished in the background.",
+                    "type": "boolean"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_count": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "link_checked_at": {
+                    "type": "string"
+                },
+                "link_status": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SongLink"
+                    }
+                },
+                "play_count": {
+                    "type": "integer"
+                },
+                "recent_plays": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                },
+                "verse_count": {
+                    "type": "integer"
+                },
+                "word_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.WordCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "word": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.YearCount": {
+            "type": "object",
+            "properties": {
+                "songs": {
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "localhost:8080",
+	BasePath:         "/",
+	Schemes:          []string{},
+	Title:            "Music Library API",
+	Description:      "API for managing an online music library.",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DuplicateCluster struct {
+	Group string    `json:"group"`
+	IDs   []SongRef `json:"ids" swaggertype:"array,string"`
+	Songs []string  `json:"songs"`
+}
+
+// duplicateClusters groups songs that are likely the same: songs by the
+// same group, after normalization, whose titles are at least threshold
+// similar. Songs in a cluster are connected through a chain of similar
+// titles, not necessarily similar to every other member.
+func duplicateClusters(songs []Song, threshold float64) []DuplicateCluster {
+	byGroup := map[string][]int{}
+	for i, song := range songs {
+		key := normalizeTitle(song.Group)
+		byGroup[key] = append(byGroup[key], i)
+	}
+
+	parent := make([]int, len(songs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, members := range byGroup {
+		for a := range members {
+			for b := a + 1; b < len(members); b++ {
+				i, j := members[a], members[b]
+				if find(i) != find(j) && similarity(songs[i].Song, songs[j].Song) >= threshold {
+					parent[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	// Clusters are listed in the order of their first song.
+	var clusters []DuplicateCluster
+	clusterOf := map[int]int{}
+	for i, song := range songs {
+		root := find(i)
+		index, ok := clusterOf[root]
+		if !ok {
+			index = len(clusters)
+			clusterOf[root] = index
+			clusters = append(clusters, DuplicateCluster{Group: songs[root].Group})
+		}
+		clusters[index].IDs = append(clusters[index].IDs, songRef(song))
+		clusters[index].Songs = append(clusters[index].Songs, song.Song)
+	}
+
+	var result []DuplicateCluster
+	for _, cluster := range clusters {
+		if len(cluster.IDs) > 1 {
+			result = append(result, cluster)
+		}
+	}
+	return result
+}
+
+// @Summary Find duplicate songs
+// @Description Get clusters of songs by the same group with the same or very similar titles, for curators to merge
+// @Produce json
+// @Param threshold query number false "Minimum title similarity from 0 to 1, defaults to DUPLICATE_THRESHOLD"
+// @Success 200 {array} DuplicateCluster
+// @Router /admin/duplicates [get]
+func getDuplicates(c *gin.Context) {
+	threshold := cfg.DuplicateThreshold
+	if value := c.Query("threshold"); value != "" {
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t <= 0 || t > 1 {
+			respondError(c, http.StatusBadRequest, codeInvalidInput, "threshold must be greater than 0 and at most 1")
+			return
+		}
+		threshold = t
+	}
+
+	var songs []Song
+	if err := dbFrom(c).Select("id", "uuid", "group", "song").Order("id").Find(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get songs")
+		return
+	}
+	clusters := duplicateClusters(songs, threshold)
+	if clusters == nil {
+		clusters = []DuplicateCluster{}
+	}
+	respondJSON(c, http.StatusOK, clusters)
+}
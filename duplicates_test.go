@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateClusters(t *testing.T) {
+	withIDType(t, idTypeInt)
+	songs := []Song{
+		{ID: 1, Group: "Queen", Song: "Bohemian Rhapsody"},
+		{ID: 2, Group: "Muse", Song: "Uprising"},
+		{ID: 3, Group: "queen", Song: "Bohemian Rhapsody!"},
+		{ID: 4, Group: "Queen", Song: "Radio Ga Ga"},
+		{ID: 5, Group: "Muse", Song: "Uprisings"},
+		{ID: 6, Group: "Blur", Song: "Uprising"},
+	}
+	tests := []struct {
+		name      string
+		threshold float64
+		want      []DuplicateCluster
+	}{
+		{
+			name:      "similar titles within a group",
+			threshold: 0.85,
+			want: []DuplicateCluster{
+				{Group: "Queen", IDs: []SongRef{"1", "3"}, Songs: []string{"Bohemian Rhapsody", "Bohemian Rhapsody!"}},
+				{Group: "Muse", IDs: []SongRef{"2", "5"}, Songs: []string{"Uprising", "Uprisings"}},
+			},
+		},
+		{
+			name:      "exact matches only",
+			threshold: 1,
+			want: []DuplicateCluster{
+				{Group: "Queen", IDs: []SongRef{"1", "3"}, Songs: []string{"Bohemian Rhapsody", "Bohemian Rhapsody!"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicateClusters(songs, tt.threshold); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("duplicateClusters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateClustersChain(t *testing.T) {
+	withIDType(t, idTypeInt)
+	// b is similar to both a and c, which aren't similar to each other.
+	songs := []Song{
+		{ID: 1, Group: "G", Song: "abcdefgh"},
+		{ID: 2, Group: "G", Song: "abcdefgX"},
+		{ID: 3, Group: "G", Song: "abcdefYX"},
+	}
+	got := duplicateClusters(songs, 0.85)
+	want := []DuplicateCluster{{Group: "G", IDs: []SongRef{"1", "2", "3"}, Songs: []string{"abcdefgh", "abcdefgX", "abcdefYX"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicateClusters() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EchoResult struct {
+	Sandbox bool          `json:"sandbox"`
+	Song    SongWithStats `json:"song"`
+}
+
+// @Summary Echo a song payload
+// @Description Sandbox for integrators: bind, validate and normalize a song exactly like POST /songs and return what would be stored, without storing anything
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "Locale used to format release dates"
+// @Param song body Song true "Song Data"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
+// @Success 200 {object} EchoResult
+// @Failure 400 {object} APIError
+// @Router /songs/echo [post]
+func echoSong(c *gin.Context) {
+	var song Song
+	if err := bindSong(c, &song); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if !validateSong(c, &song) {
+		return
+	}
+	addDefaultLink(&song)
+	localizeSong(c, &song)
+	respondJSON(c, http.StatusOK, EchoResult{
+		Sandbox: true,
+		Song:    withStats(song),
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEchoSong(t *testing.T) {
+	previous := cfg
+	cfg = loadConfig()
+	cfg.Location = time.UTC
+	t.Cleanup(func() { cfg = previous })
+	r := gin.New()
+	r.POST("/songs/echo", echoSong)
+
+	post := func(body, acceptLanguage string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/songs/echo", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", acceptLanguage)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"group":"Queen","song":"Innuendo","release_date":"04.02.1991","text":"one\n\ntwo","link":"https://www.youtube.com/watch?v=1"}`, "de")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var result EchoResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Sandbox {
+		t.Error("sandbox = false, want true")
+	}
+	if result.Song.ReleaseDate != "04.02.1991" {
+		t.Errorf("release date = %q, want it localized as 04.02.1991", result.Song.ReleaseDate)
+	}
+	if len(result.Song.Links) != 1 || result.Song.Links[0].URL != "https://www.youtube.com/watch?v=1" {
+		t.Errorf("links = %+v, want the link as the only entry", result.Song.Links)
+	}
+
+	if w := post(`{"group":"Queen","song":"Innuendo","release_date":"someday"}`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid song status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := post(`{"group":"Queen"`, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed body status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SongDetail is the metadata an enrichment provider knows about a song.
+// Empty fields are unknown.
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Enricher looks up metadata for a song.
+type Enricher interface {
+	Enrich(ctx context.Context, group, song string) (SongDetail, error)
+}
+
+var errNoDetail = errors.New("song not found")
+
+// enrichers maps the names accepted in ENRICHMENT_PROVIDER to constructors.
+var enrichers = map[string]func(Config) Enricher{
+	"http":        func(c Config) Enricher { return httpEnricher{baseURL: c.EnrichmentURL} },
+	"musicbrainz": func(Config) Enricher { return musicBrainzEnricher{baseURL: "https://musicbrainz.org/ws/2"} },
+}
+
+// enricher is built from ENRICHMENT_PROVIDER at startup, nil when disabled.
+var enricher Enricher
+
+// newEnricher builds the chain of the named providers, or nil without any.
+func newEnricher(c Config) Enricher {
+	var chain enricherChain
+	for _, name := range c.EnrichmentProviders {
+		chain = append(chain, enrichers[name](c))
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	if len(chain) == 1 {
+		return chain[0]
+	}
+	return chain
+}
+
+// enricherChain tries its providers in order and returns the first detail
+// found.
+type enricherChain []Enricher
+
+func (chain enricherChain) Enrich(ctx context.Context, group, song string) (SongDetail, error) {
+	var errs []error
+	for _, e := range chain {
+		detail, err := e.Enrich(ctx, group, song)
+		if err == nil {
+			return detail, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return SongDetail{}, errors.Join(errs...)
+}
+
+var enrichmentClient = &http.Client{}
+
+// enrichmentLimiter keeps every outbound enrichment call, whichever provider
+// or request it is for, within ENRICHMENT_RPS.
+var enrichmentLimiter *rateLimiter
+
+// enrichmentSlots keeps at most ENRICHMENT_CONCURRENCY enrichment calls in
+// flight across all requests. Further calls queue until a slot is free or
+// their context ends.
+var enrichmentSlots semaphore
+
+func getJSON(ctx context.Context, u string, v any) error {
+	if err := enrichmentSlots.acquire(ctx); err != nil {
+		return err
+	}
+	defer enrichmentSlots.release()
+	if err := enrichmentLimiter.wait(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "music_library/1.0")
+	resp, err := enrichmentClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNoDetail
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s responded with %s", req.URL.Host, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// httpEnricher queries a service serving GET /info?group=&song= with a
+// SongDetail body.
+type httpEnricher struct {
+	baseURL string
+}
+
+func (e httpEnricher) Enrich(ctx context.Context, group, song string) (SongDetail, error) {
+	var detail SongDetail
+	query := url.Values{"group": {group}, "song": {song}}
+	err := getJSON(ctx, strings.TrimSuffix(e.baseURL, "/")+"/info?"+query.Encode(), &detail)
+	return detail, err
+}
+
+// musicBrainzEnricher looks up the release date and a link to the recording
+// on MusicBrainz. It has no lyrics.
+type musicBrainzEnricher struct {
+	baseURL string
+}
+
+func (e musicBrainzEnricher) Enrich(ctx context.Context, group, song string) (SongDetail, error) {
+	var result struct {
+		Recordings []struct {
+			ID               string `json:"id"`
+			FirstReleaseDate string `json:"first-release-date"`
+		} `json:"recordings"`
+	}
+	query := url.Values{
+		"query": {fmt.Sprintf("recording:%q AND artist:%q", song, group)},
+		"fmt":   {"json"},
+		"limit": {"1"},
+	}
+	if err := getJSON(ctx, e.baseURL+"/recording?"+query.Encode(), &result); err != nil {
+		return SongDetail{}, err
+	}
+	if len(result.Recordings) == 0 {
+		return SongDetail{}, errNoDetail
+	}
+	recording := result.Recordings[0]
+	return SongDetail{
+		ReleaseDate: recording.FirstReleaseDate,
+		Link:        "https://musicbrainz.org/recording/" + recording.ID,
+	}, nil
+}
+
+// parseHTTPURL parses raw as an absolute http or https URL with a host.
+func parseHTTPURL(raw string) (*url.URL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// allowedEnrichmentLink reports whether a link returned by a provider may be
+// stored: it has to be an http(s) URL and, with ENRICHMENT_ALLOWED_DOMAINS,
+// on one of the listed domains or their subdomains.
+func allowedEnrichmentLink(link string) bool {
+	u, ok := parseHTTPURL(link)
+	if !ok {
+		return false
+	}
+	if len(cfg.EnrichmentAllowedDomains) == 0 {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	return slices.ContainsFunc(cfg.EnrichmentAllowedDomains, func(domain string) bool {
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	})
+}
+
+// enrichFields fills the empty fields of song from the configured
+// providers, giving them up to timeout, and returns the columns it set.
+func enrichFields(ctx context.Context, song *Song, timeout time.Duration) ([]string, error) {
+	if enricher == nil || (song.ReleaseDate != "" && song.Text != "" && song.Link != "") {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	detail, err := enricher.Enrich(ctx, song.Group, song.Song)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	// Providers may only know the year or month, which isn't a release date
+	// we can store.
+	if date, ok := normalizeReleaseDate(detail.ReleaseDate, ""); ok && date != "" && song.ReleaseDate == "" {
+		song.ReleaseDate = date
+		fields = append(fields, "release_date")
+	}
+	if detail.Text != "" && song.Text == "" {
+		song.Text = detail.Text
+		fields = append(fields, "text")
+	}
+	if detail.Link != "" && song.Link == "" {
+		if allowedEnrichmentLink(detail.Link) {
+			song.Link = detail.Link
+			fields = append(fields, "link")
+		} else {
+			logrus.Warnf("Dropped enrichment link %q of %q by %q, it isn't an http(s) URL on an allowed domain", detail.Link, song.Song, song.Group)
+		}
+	}
+	return fields, nil
+}
+
+// enrichSong is enrichFields for new songs. Enrichment is best effort:
+// failures are logged and the song is stored as sent. When the providers
+// time out, song.EnrichmentPending is set so enrichment can be finished
+// with finishEnrichment once the song is stored.
+func enrichSong(ctx context.Context, song *Song) {
+	_, err := enrichFields(ctx, song, cfg.EnrichmentTimeout)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		song.EnrichmentPending = true
+	case err != nil:
+		logrus.Warnf("Failed to enrich %q by %q: %v", song.Song, song.Group, err)
+	}
+}
+
+// enrichmentActor is recorded in the history of songs enriched after they
+// were created.
+const enrichmentActor = "enrichment"
+
+// finishEnrichment retries enrichment of a song whose providers timed out
+// while it was created, with ENRICHMENT_BACKGROUND_TIMEOUT_MS to respond.
+func finishEnrichment(id uint) {
+	// Only one runner enriches a song at a time.
+	lock, ok, err := tryJobLock("enrichment:" + strconv.FormatUint(uint64(id), 10))
+	if err != nil || !ok {
+		if err != nil {
+			logrus.Warnf("Failed to enrich song %d: %v", id, err)
+		}
+		return
+	}
+	defer lock.release()
+
+	var song Song
+	if err := db.Take(&song, id).Error; err != nil {
+		logrus.Warnf("Failed to load song %d for enrichment: %v", id, err)
+		return
+	}
+	fields, err := enrichFields(context.Background(), &song, cfg.EnrichmentBackgroundTimeout)
+	if err == nil && len(fields) > 0 {
+		err = saveEnrichment(db, song, fields, enrichmentActor)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to enrich song %d: %v", id, err)
+	}
+}
+
+// saveEnrichment stores fields of song, as filled in by enrichFields, with a
+// revision of the song as it was before. It fails with errSongModified if
+// the song changed since it was loaded, and with an invalidSongError if the
+// enriched song doesn't pass the checks.
+func saveEnrichment(tx *gorm.DB, song Song, fields []string, actor string) error {
+	var previous Song
+	if err := tx.Take(&previous, song.ID).Error; err != nil {
+		return err
+	}
+	if !previous.UpdatedAt.Equal(song.UpdatedAt) {
+		return errSongModified
+	}
+	if err := checkSong(&song, checkOptions{truncate: true, warn: func(string) {}}); err != nil {
+		return err
+	}
+	columns := slices.DeleteFunc(slices.Clone(fields), func(field string) bool { return field == "link" })
+	if slices.Contains(fields, "text") {
+		columns = append(columns, lyricStatsColumns...)
+	}
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if err := saveRevisions(tx, []Song{previous}, actor, actionEnrich); err != nil {
+			return err
+		}
+		result := tx.Model(&song).Where("updated_at = ?", previous.UpdatedAt).
+			Select(append(columns, "flagged")).Updates(&song)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return errSongModified
+		}
+		if result.Error != nil || !slices.Contains(fields, "link") {
+			return result.Error
+		}
+		link := song.Link
+		song.Link = previous.Link
+		return setPrimaryLink(tx, &song, link)
+	})
+	if err == nil {
+		onSongChanged(song, songUpdated)
+	}
+	return err
+}
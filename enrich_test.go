@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubEnricher returns detail, or err when set.
+type stubEnricher struct {
+	detail SongDetail
+	err    error
+}
+
+func (e stubEnricher) Enrich(context.Context, string, string) (SongDetail, error) {
+	return e.detail, e.err
+}
+
+func withEnricher(t *testing.T, e Enricher) {
+	t.Helper()
+	previous := enricher
+	enricher = e
+	t.Cleanup(func() { enricher = previous })
+}
+
+func withAllowedDomains(t *testing.T, domains ...string) {
+	t.Helper()
+	previous := cfg.EnrichmentAllowedDomains
+	cfg.EnrichmentAllowedDomains = domains
+	t.Cleanup(func() { cfg.EnrichmentAllowedDomains = previous })
+}
+
+func TestAllowedEnrichmentLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []string
+		link    string
+		want    bool
+	}{
+		{"any domain without allowlist", nil, "https://example.com/song", true},
+		{"listed domain", []string{"musicbrainz.org"}, "https://musicbrainz.org/recording/1", true},
+		{"subdomain", []string{"musicbrainz.org"}, "https://beta.musicbrainz.org/recording/1", true},
+		{"host is case-insensitive", []string{"musicbrainz.org"}, "https://MusicBrainz.org/recording/1", true},
+		{"other domain", []string{"musicbrainz.org"}, "https://evil.example/recording/1", false},
+		{"suffix without dot", []string{"musicbrainz.org"}, "https://notmusicbrainz.org/", false},
+		{"javascript scheme", nil, "javascript:alert(1)", false},
+		{"no host", nil, "https:///path", false},
+		{"relative", nil, "/recording/1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withAllowedDomains(t, tt.domains...)
+			if got := allowedEnrichmentLink(tt.link); got != tt.want {
+				t.Errorf("allowedEnrichmentLink(%q) = %v, want %v", tt.link, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnrichFieldsDropsDisallowedLink(t *testing.T) {
+	withAllowedDomains(t, "musicbrainz.org")
+	withEnricher(t, stubEnricher{detail: SongDetail{Text: "Oh baby, don't you know I suffer?", Link: "https://evil.example/song"}})
+
+	song := Song{Group: "Muse", Song: "Supermassive Black Hole"}
+	fields, err := enrichFields(context.Background(), &song, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if song.Link != "" {
+		t.Errorf("link = %q, want it dropped", song.Link)
+	}
+	if len(fields) != 1 || fields[0] != "text" {
+		t.Errorf("fields = %v, want [text]", fields)
+	}
+}
+
+// slowEnricher blocks until the lookup is given up.
+type slowEnricher struct{}
+
+func (slowEnricher) Enrich(ctx context.Context, _, _ string) (SongDetail, error) {
+	<-ctx.Done()
+	return SongDetail{}, ctx.Err()
+}
+
+func TestEnrichSongPending(t *testing.T) {
+	previous := cfg.EnrichmentTimeout
+	cfg.EnrichmentTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { cfg.EnrichmentTimeout = previous })
+
+	tests := []struct {
+		name        string
+		enricher    Enricher
+		wantPending bool
+		wantText    string
+	}{
+		{"timed out", slowEnricher{}, true, ""},
+		{"found", stubEnricher{detail: SongDetail{Text: "Is this the real life?"}}, false, "Is this the real life?"},
+		{"failed", stubEnricher{err: errNoDetail}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEnricher(t, tt.enricher)
+			song := Song{Group: "Queen", Song: "Bohemian Rhapsody"}
+			enrichSong(context.Background(), &song)
+			if song.EnrichmentPending != tt.wantPending {
+				t.Errorf("enrichment_pending = %v, want %v", song.EnrichmentPending, tt.wantPending)
+			}
+			if song.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", song.Text, tt.wantText)
+			}
+		})
+	}
+}
+
+// countingEnricher counts its calls and returns detail, or err when set.
+type countingEnricher struct {
+	stubEnricher
+	calls *int
+}
+
+func (e countingEnricher) Enrich(ctx context.Context, group, song string) (SongDetail, error) {
+	*e.calls++
+	return e.stubEnricher.Enrich(ctx, group, song)
+}
+
+func TestEnricherChain(t *testing.T) {
+	found := SongDetail{ReleaseDate: "1975-10-31"}
+	tests := []struct {
+		name      string
+		first     stubEnricher
+		second    stubEnricher
+		want      SongDetail
+		wantErr   bool
+		wantCalls [2]int
+	}{
+		{"first succeeds", stubEnricher{detail: found}, stubEnricher{err: errNoDetail}, found, false, [2]int{1, 0}},
+		{"first fails, second succeeds", stubEnricher{err: errNoDetail}, stubEnricher{detail: found}, found, false, [2]int{1, 1}},
+		{"both fail", stubEnricher{err: errNoDetail}, stubEnricher{err: errors.New("unavailable")}, SongDetail{}, true, [2]int{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [2]int
+			chain := enricherChain{
+				countingEnricher{tt.first, &calls[0]},
+				countingEnricher{tt.second, &calls[1]},
+			}
+			got, err := chain.Enrich(context.Background(), "Queen", "Bohemian Rhapsody")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Enrich() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Enrich() = %+v, want %+v", got, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEnricherChainStopsWhenCancelled(t *testing.T) {
+	var calls int
+	chain := enricherChain{slowEnricher{}, countingEnricher{stubEnricher{detail: SongDetail{Text: "la"}}, &calls}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := chain.Enrich(ctx, "Queen", "Bohemian Rhapsody")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Enrich() error = %v, want %v", err, context.Canceled)
+	}
+	if calls != 0 {
+		t.Errorf("the chain went on to the next provider %d times after the context ended", calls)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Error codes returned in APIError.Code.
+const (
+	codeInvalidInput         = "INVALID_INPUT"
+	codeMalformedJSON        = "MALFORMED_JSON"
+	codeValidationFailed     = "VALIDATION_FAILED"
+	codeNotFound             = "NOT_FOUND"
+	codeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	codePreconditionFailed   = "PRECONDITION_FAILED"
+	codeConflict             = "CONFLICT"
+	codeInternalError        = "INTERNAL_ERROR"
+	codeTimeout              = "TIMEOUT"
+	codeUnavailable          = "SERVICE_UNAVAILABLE"
+)
+
+// APIError is the body of every error response.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"error"`
+	// Fields names the conflicting fields of a CONFLICT caused by a unique
+	// index.
+	Fields []string `json:"fields,omitempty"`
+}
+
+func respondError(c *gin.Context, status int, code, message string) {
+	respondJSON(c, status, APIError{Code: code, Message: message})
+}
+
+const mimeProblemJSON = "application/problem+json"
+
+// problemTypePrefix is prepended to the lowercased error code to form the
+// type URI of a problem.
+const problemTypePrefix = "urn:music-library:error:"
+
+// Problem is an error in RFC 7807 format, sent instead of APIError to
+// clients that accept application/problem+json. The error code is kept as
+// an extension member.
+type Problem struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Detail   string   `json:"detail"`
+	Instance string   `json:"instance"`
+	Code     string   `json:"code"`
+	Fields   []string `json:"fields,omitempty"`
+}
+
+func wantsProblem(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), mimeProblemJSON)
+}
+
+// respondProblem writes err as a problem+json response.
+func respondProblem(c *gin.Context, status int, err APIError) {
+	problem := Problem{
+		Type:     problemTypePrefix + strings.ToLower(err.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Message,
+		Instance: c.Request.URL.RequestURI(),
+		Code:     err.Code,
+		Fields:   err.Fields,
+	}
+	var data []byte
+	var marshalErr error
+	if cfg.PrettyJSON || c.Query("pretty") == "true" {
+		data, marshalErr = json.MarshalIndent(problem, "", "    ")
+	} else {
+		data, marshalErr = json.Marshal(problem)
+	}
+	if marshalErr != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, mimeProblemJSON, data)
+}
+
+// recovery turns panics in handlers into a logged stack trace and a generic
+// INTERNAL_ERROR response, without exposing any details to the client.
+func recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logrus.WithFields(logrus.Fields{
+					"request_id": c.GetHeader("X-Request-ID"),
+					"method":     c.Request.Method,
+					"path":       c.Request.URL.Path,
+					"stack":      string(debug.Stack()),
+				}).Errorf("Panic while handling request: %v", r)
+
+				c.Abort()
+				if !c.Writer.Written() {
+					respondError(c, http.StatusInternalServerError, codeInternalError, "Internal server error")
+				}
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecovery(t *testing.T) {
+	var logged strings.Builder
+	logrus.SetOutput(&logged)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	r := gin.New()
+	r.Use(recovery())
+	r.GET("/panic", func(c *gin.Context) { panic("secret connection string") })
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := serve(r, "/panic")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var body APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != codeInternalError || strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("body = %s, want a generic %s error", w.Body, codeInternalError)
+	}
+	if !strings.Contains(logged.String(), "secret connection string") {
+		t.Errorf("log = %q, want the panic value", logged.String())
+	}
+
+	if code := serve(r, "/ok").Code; code != http.StatusOK {
+		t.Errorf("status after a panic = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestRespondErrorAsProblem(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/:id", func(c *gin.Context) {
+		respondError(c, http.StatusNotFound, codeNotFound, "Song not found")
+	})
+	get := func(accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/songs/7?include=links", nil)
+		req.Header.Set("Accept", accept)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("application/json, application/problem+json;q=0.9")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if got := w.Header().Get("Content-Type"); got != mimeProblemJSON {
+		t.Errorf("Content-Type = %q, want %q", got, mimeProblemJSON)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type:     "urn:music-library:error:not_found",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "Song not found",
+		Instance: "/songs/7?include=links",
+		Code:     codeNotFound,
+	}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("problem = %+v, want %+v", problem, want)
+	}
+
+	w = get("application/json")
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var apiErr APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &apiErr); err != nil || !reflect.DeepEqual(apiErr, APIError{Code: codeNotFound, Message: "Song not found"}) {
+		t.Errorf("body = %s, want an APIError", w.Body)
+	}
+}
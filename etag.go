@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errSongModified = errors.New("song modified concurrently")
+
+// songETag identifies a version of song. It changes whenever the song is
+// saved, since updated_at is bumped on every write. Microseconds match the
+// precision PostgreSQL stores timestamps with. The song is named by its
+// songRef, so the integer id stays hidden with ID_TYPE=uuid.
+func songETag(song Song) string {
+	return fmt.Sprintf(`"%s-%x"`, songRef(song), song.UpdatedAt.UnixMicro())
+}
+
+// etagMatches compares the ETags in an If-None-Match header to etag with the
+// weak comparison, which ignores the W/ prefix.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatchesStrong compares the ETags in an If-Match header to etag with the
+// strong comparison RFC 9110 requires for it: weak tags never match.
+func etagMatchesStrong(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch enforces the If-Match precondition for a write to song. It
+// writes the error response and returns false when the header is missing or
+// the client's copy is stale.
+func checkIfMatch(c *gin.Context, song Song) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		respondError(c, http.StatusPreconditionRequired, codePreconditionFailed, "If-Match header is required")
+		return false
+	}
+	if !etagMatchesStrong(header, songETag(song)) {
+		respondError(c, http.StatusPreconditionFailed, codePreconditionFailed, "Song has been modified since it was fetched")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSongETag(t *testing.T) {
+	song := Song{ID: 42, UUID: "0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00", UpdatedAt: time.UnixMicro(0x5f5e100)}
+	tests := []struct {
+		idType string
+		want   string
+	}{
+		{idTypeInt, `"42-5f5e100"`},
+		{idTypeUUID, `"0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00-5f5e100"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.idType, func(t *testing.T) {
+			withIDType(t, tt.idType)
+			got := songETag(song)
+			if got != tt.want {
+				t.Errorf("songETag() = %s, want %s", got, tt.want)
+			}
+			if tt.idType == idTypeUUID && strings.Contains(got, "42") {
+				t.Errorf("songETag() = %s leaks the integer id", got)
+			}
+		})
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	withIDType(t, idTypeInt)
+	song := Song{ID: 42, UpdatedAt: time.UnixMicro(0x5f5e100)}
+	tests := []struct {
+		name       string
+		header     string
+		wantOK     bool
+		wantStatus int
+	}{
+		{"missing", "", false, http.StatusPreconditionRequired},
+		{"current", `"42-5f5e100"`, true, http.StatusOK},
+		{"one of several", `"42-1", "42-5f5e100"`, true, http.StatusOK},
+		{"any", "*", true, http.StatusOK},
+		{"stale", `"42-1"`, false, http.StatusPreconditionFailed},
+		{"weak", `W/"42-5f5e100"`, false, http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/songs/42", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+			if ok := checkIfMatch(c, song); ok != tt.wantOK {
+				t.Errorf("checkIfMatch() = %v, want %v", ok, tt.wantOK)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
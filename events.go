@@ -0,0 +1,47 @@
+package main
+
+import "sync"
+
+// Kinds of song changes published through onSongChanged.
+const (
+	songCreated = "created"
+	songUpdated = "updated"
+	// songRegrouped is an update that moved the song to another group.
+	songRegrouped = "regrouped"
+	songDeleted   = "deleted"
+)
+
+// changeTypeFor returns the kind of an update that changed the group of a
+// song from previous to current.
+func changeTypeFor(previous, current string) string {
+	if previous != current {
+		return songRegrouped
+	}
+	return songUpdated
+}
+
+type songChangeHandler func(song Song, changeType string)
+
+var songChangeHandlers struct {
+	mu       sync.RWMutex
+	handlers []songChangeHandler
+}
+
+// subscribeSongChanges registers handler to be called on every song change.
+func subscribeSongChanges(handler songChangeHandler) {
+	songChangeHandlers.mu.Lock()
+	defer songChangeHandlers.mu.Unlock()
+	songChangeHandlers.handlers = append(songChangeHandlers.handlers, handler)
+}
+
+// onSongChanged notifies the subscribers, typically caches, that a song
+// changed. Handlers call it after the change is committed, so subscribers
+// never drop state for a change that was rolled back. Only the id and uuid
+// of song need to be loaded.
+func onSongChanged(song Song, changeType string) {
+	songChangeHandlers.mu.RLock()
+	defer songChangeHandlers.mu.RUnlock()
+	for _, handler := range songChangeHandlers.handlers {
+		handler(song, changeType)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestChangeTypeFor(t *testing.T) {
+	if got := changeTypeFor("Queen", "Queen"); got != songUpdated {
+		t.Errorf("changeTypeFor(same group) = %q, want %q", got, songUpdated)
+	}
+	if got := changeTypeFor("Queen", "Yes"); got != songRegrouped {
+		t.Errorf("changeTypeFor(other group) = %q, want %q", got, songRegrouped)
+	}
+}
+
+func TestOnSongChangedNotifiesSubscribers(t *testing.T) {
+	songChangeHandlers.mu.RLock()
+	previous := songChangeHandlers.handlers
+	songChangeHandlers.mu.RUnlock()
+	t.Cleanup(func() {
+		songChangeHandlers.mu.Lock()
+		songChangeHandlers.handlers = previous
+		songChangeHandlers.mu.Unlock()
+	})
+
+	type change struct {
+		id         uint
+		changeType string
+	}
+	var first, second []change
+	subscribeSongChanges(func(song Song, changeType string) { first = append(first, change{song.ID, changeType}) })
+	subscribeSongChanges(func(song Song, changeType string) { second = append(second, change{song.ID, changeType}) })
+
+	onSongChanged(Song{ID: 7}, songCreated)
+	onSongChanged(Song{ID: 7}, songDeleted)
+
+	want := []change{{7, songCreated}, {7, songDeleted}}
+	for name, got := range map[string][]change{"first": first, "second": second} {
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("%s subscriber got %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// maxFuzzyCandidates bounds how many rows are compared in Go for a fuzzy
+// match. They are the songs with the most trigram-similar search keys.
+const maxFuzzyCandidates = 200
+
+type ExistsResult struct {
+	Exists    bool    `json:"exists"`
+	MatchType string  `json:"match_type,omitempty"`
+	Score     float64 `json:"score,omitempty"`
+	Match     *Song   `json:"match"`
+}
+
+// @Summary Check whether a song exists
+// @Description Look for a song by group and title, first exactly and then fuzzily
+// @Produce json
+// @Param group query string true "Group Name"
+// @Param song query string true "Song Name"
+// @Success 200 {object} ExistsResult
+// @Router /songs/exists [get]
+func getSongExists(c *gin.Context) {
+	group, title := strings.TrimSpace(c.Query("group")), strings.TrimSpace(c.Query("song"))
+	if group == "" || title == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Both group and song are required")
+		return
+	}
+
+	var song Song
+	if err := dbFrom(c).Where(`"group" = ? AND song = ?`, group, title).Take(&song).Error; err == nil {
+		respondJSON(c, http.StatusOK, ExistsResult{Exists: true, MatchType: "exact", Score: 1, Match: &song})
+		return
+	}
+
+	// Candidates share a search key or have both keys trigram-similar, the
+	// most similar first, so a typo in the group and the title still finds
+	// the song and the cap drops the least likely matches.
+	groupKey, songKey := searchKey(group), searchKey(title)
+	var candidates []Song
+	if err := dbFrom(c).
+		Where(`group_key = ? OR song_key = ? OR (group_key % ? AND song_key % ?)`, groupKey, songKey, groupKey, songKey).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "similarity(group_key, ?) + similarity(song_key, ?) DESC, id",
+			Vars:               []any{groupKey, songKey},
+			WithoutParentheses: true,
+		}}).
+		Limit(maxFuzzyCandidates).
+		Find(&candidates).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to look up song")
+		return
+	}
+
+	result := ExistsResult{}
+	for i := range candidates {
+		score := (similarity(candidates[i].Group, group) + similarity(candidates[i].Song, title)) / 2
+		if score >= cfg.FuzzyMatchThreshold && score > result.Score {
+			result = ExistsResult{Exists: true, MatchType: "fuzzy", Score: score, Match: &candidates[i]}
+		}
+	}
+	respondJSON(c, http.StatusOK, result)
+}
+
+// @Summary Look up a song by name
+// @Description Get the song with exactly this group and title. Fails with 409 if more than one song matches.
+// @Produce json
+// @Param group query string true "Group Name"
+// @Param song query string true "Song Name"
+// @Param Accept-Language header string false "Locale used to format release dates"
+// @Success 200 {object} Song
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Router /songs/lookup [get]
+func lookupSong(c *gin.Context) {
+	group, title := strings.TrimSpace(c.Query("group")), strings.TrimSpace(c.Query("song"))
+	if group == "" || title == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Both group and song are required")
+		return
+	}
+
+	// Two are enough to tell a unique match from an ambiguous one.
+	var songs []Song
+	if err := dbFrom(c).Preload("Links").Where(`"group" = ? AND song = ?`, group, title).Order("id").Limit(2).Find(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to look up song")
+		return
+	}
+	switch len(songs) {
+	case 0:
+		respondError(c, http.StatusNotFound, codeNotFound, "Song not found")
+	case 1:
+		localizeSong(c, &songs[0])
+		respondJSON(c, http.StatusOK, songs[0])
+	default:
+		respondError(c, http.StatusConflict, codeConflict, "More than one song has this group and title")
+	}
+}
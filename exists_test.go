@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetSongExistsWithTyposInGroupAndTitle(t *testing.T) {
+	testDB(t)
+	cfg.FuzzyMatchThreshold = 0.8
+	song := Song{Group: "Queen", Song: "Bohemian Rhapsody"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/songs/exists", getSongExists)
+
+	w := httptest.NewRecorder()
+	query := url.Values{"group": {"Quen"}, "song": {"Bohemian Rapsody"}}
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/exists?"+query.Encode(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var result ExistsResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Exists || result.MatchType != "fuzzy" || result.Match == nil || result.Match.ID != song.ID {
+		t.Errorf("result = %+v, want a fuzzy match of song %d", result, song.ID)
+	}
+}
+
+func TestLookupSongRequiresGroupAndSong(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/lookup", lookupSong)
+	for _, query := range []string{"", "group=Queen", "song=Innuendo", "group=%20&song=Innuendo"} {
+		t.Run(query, func(t *testing.T) {
+			if w := serve(r, "/songs/lookup?"+query); w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestLookupSong(t *testing.T) {
+	testDB(t)
+	song := Song{Group: "Queen", Song: "Innuendo", ReleaseDate: "1991-02-04"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/songs/lookup", lookupSong)
+
+	query := url.Values{"group": {" Queen "}, "song": {"Innuendo"}}
+	w := serve(r, "/songs/lookup?"+query.Encode())
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got Song
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Group != "Queen" || got.Song != "Innuendo" {
+		t.Errorf("song = %+v, want Queen - Innuendo", got)
+	}
+
+	query.Set("song", "innuendo")
+	if w := serve(r, "/songs/lookup?"+query.Encode()); w.Code != http.StatusNotFound {
+		t.Errorf("status for a different title = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
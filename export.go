@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many songs are loaded at a time while exporting.
+const exportBatchSize = 500
+
+// @Summary Export songs as NDJSON
+// @Description Stream every song matching the listing filters as newline-delimited JSON, one song per line, in ID order
+// @Produce application/x-ndjson
+// @Param group query string false "Filter by group"
+// @Param song query string false "Filter by song"
+// @Param exclude_group query string false "Comma-separated groups to leave out (at most 50)"
+// @Param safe query bool false "Exclude flagged songs"
+// @Param has_link query bool false "Only songs with (true) or without (false) a valid link"
+// @Param has_cover query bool false "Only songs with (true) or without (false) a cover"
+// @Param link_status query string false "Only songs whose link was last checked as ok, broken or unknown"
+// @Param has_text query bool false "Only songs with (true) or without (false) lyrics"
+// @Param updated_since query string false "Only songs updated since this RFC 3339 time, or since the start of this YYYY-MM-DD date in DEFAULT_TIMEZONE"
+// @Success 200 {string} string "One Song per line"
+// @Router /songs/export.ndjson [get]
+func exportSongs(c *gin.Context) {
+	if !checkSongFilters(c) {
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	var songs []Song
+	err := filterSongs(c, dbFrom(c)).Preload("Links").
+		FindInBatches(&songs, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, song := range songs {
+				line, err := publicJSON(song)
+				if err != nil {
+					return err
+				}
+				if err := encoder.Encode(line); err != nil {
+					return err
+				}
+			}
+			c.Writer.Flush()
+			return nil
+		}).Error
+	if err != nil {
+		// The status is already sent, so all we can do is cut the stream short.
+		logrus.Errorf("Failed to export songs: %v", err)
+	}
+}
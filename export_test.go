@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExportSongsRejectsTooManyExcludedGroups(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/export.ndjson", exportSongs)
+	groups := make([]string, maxExcludedGroups+1)
+	for i := range groups {
+		groups[i] = "g" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	w := serve(r, "/songs/export.ndjson?exclude_group="+strings.Join(groups, ","))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExportSongs(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Yes", Song: "Roundabout"},
+		{Group: "Queen", Song: "Bohemian Rhapsody"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/songs/export.ndjson", exportSongs)
+
+	w := serve(r, "/songs/export.ndjson?group=Queen")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", got)
+	}
+	var titles []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var song Song
+		if err := json.Unmarshal(scanner.Bytes(), &song); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		titles = append(titles, song.Song)
+	}
+	if want := []string{"Innuendo", "Bohemian Rhapsody"}; strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Errorf("exported %q, want %q in id order", titles, want)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const featureEnvPrefix = "FEATURE_"
+
+// experimentalFeatures are the features that can be turned off, each gating
+// the route of the same name: FEATURE_LYRICS_SEARCH=off hides
+// /lyrics/search, for example. The list is kept in sync with the README.
+var experimentalFeatures = []string{
+	"recent", "popular", "longest", "trending", "incomplete", "suggest",
+	"stream", "diff", "card", "neighbors", "lyric_similar", "echo",
+	"import_jobs", "random_verse", "digest", "completeness", "lyrics_search",
+}
+
+// loadFeatures reads FEATURE_<NAME>=on|off variables from the environment.
+// Features that are not mentioned are enabled.
+func loadFeatures() map[string]bool {
+	features := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, featureEnvPrefix) {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(key, featureEnvPrefix))
+		if !slices.Contains(experimentalFeatures, name) {
+			logrus.Warnf("Unknown feature %s, expected one of %s", key, strings.Join(experimentalFeatures, ", "))
+			continue
+		}
+		switch strings.ToLower(value) {
+		case "on", "true", "1":
+			features[name] = true
+		case "off", "false", "0":
+			features[name] = false
+		default:
+			logrus.Warnf("Invalid value %q for %s, leaving feature enabled", value, key)
+		}
+	}
+	return features
+}
+
+func featureEnabled(name string) bool {
+	enabled, ok := cfg.Features[name]
+	return !ok || enabled
+}
+
+// requireFeature responds with 404 for routes whose feature is disabled.
+func requireFeature(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !featureEnabled(name) {
+			c.Abort()
+			respondError(c, http.StatusNotFound, codeNotFound, "Not found")
+			return
+		}
+		c.Next()
+	}
+}
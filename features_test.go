@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoadFeatures(t *testing.T) {
+	t.Setenv("FEATURE_RECENT", "off")
+	t.Setenv("FEATURE_LYRICS_SEARCH", "FALSE")
+	t.Setenv("FEATURE_DIGEST", "on")
+	t.Setenv("FEATURE_CARD", "maybe")
+	t.Setenv("FEATURE_TELEPORT", "on")
+
+	features := loadFeatures()
+	want := map[string]bool{"recent": false, "lyrics_search": false, "digest": true}
+	for name, enabled := range want {
+		if got, ok := features[name]; !ok || got != enabled {
+			t.Errorf("features[%q] = %v, %v, want %v", name, got, ok, enabled)
+		}
+	}
+	for _, name := range []string{"card", "teleport"} {
+		if _, ok := features[name]; ok {
+			t.Errorf("features[%q] is set, want invalid and unknown features left out", name)
+		}
+	}
+}
+
+func TestRequireFeature(t *testing.T) {
+	previous := cfg
+	cfg.Features = map[string]bool{"recent": false, "digest": true}
+	t.Cleanup(func() { cfg = previous })
+
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/songs/recent", requireFeature("recent"), ok)
+	r.GET("/digest", requireFeature("digest"), ok)
+	r.GET("/songs/popular", requireFeature("popular"), ok)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/songs/recent", http.StatusNotFound},
+		{"/digest", http.StatusOK},
+		{"/songs/popular", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+}
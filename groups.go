@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupCount struct {
+	Group string `json:"group"`
+	Songs int64  `json:"songs"`
+}
+
+// groupsCache holds the result of the distinct groups query for a limited
+// time. It is invalidated by song changes that may change the set of groups.
+// Every invalidation bumps the generation, so a result queried before an
+// invalidation is never stored after it.
+type groupsCache struct {
+	mu         sync.RWMutex
+	groups     []GroupCount
+	expiresAt  time.Time
+	generation uint64
+}
+
+var groupCache groupsCache
+
+func init() {
+	subscribeSongChanges(func(_ Song, changeType string) {
+		if changeType != songUpdated {
+			groupCache.invalidate()
+		}
+	})
+}
+
+// get returns the cached groups, or the current generation to pass to set
+// when they have to be queried.
+func (gc *groupsCache) get() ([]GroupCount, uint64, bool) {
+	gc.mu.RLock()
+	defer gc.mu.RUnlock()
+	if gc.groups == nil || time.Now().After(gc.expiresAt) {
+		return nil, gc.generation, false
+	}
+	return gc.groups, gc.generation, true
+}
+
+// set stores groups queried at generation, unless the cache was invalidated
+// since.
+func (gc *groupsCache) set(groups []GroupCount, ttl time.Duration, generation uint64) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	if generation != gc.generation {
+		return
+	}
+	gc.groups = groups
+	gc.expiresAt = time.Now().Add(ttl)
+}
+
+func (gc *groupsCache) invalidate() {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gc.groups = nil
+	gc.generation++
+}
+
+// @Summary Get all groups
+// @Description Get the distinct groups in the library with their song counts
+// @Produce json
+// @Success 200 {array} GroupCount
+// @Router /groups [get]
+func getGroups(c *gin.Context) {
+	groups, generation, ok := groupCache.get()
+	if ok {
+		respondJSON(c, http.StatusOK, groups)
+		return
+	}
+
+	groups = []GroupCount{}
+	if err := dbFrom(c).Model(&Song{}).
+		Select(`"group", count(*) AS songs`).
+		Group(`"group"`).
+		Order(`"group"`).
+		Scan(&groups).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to load groups")
+		return
+	}
+
+	groupCache.set(groups, cfg.GroupsCacheTTL, generation)
+	respondJSON(c, http.StatusOK, groups)
+}
+
+const maxTopGroupsLimit = 100
+
+type GroupRank struct {
+	Rank int `json:"rank"`
+	GroupCount
+}
+
+// @Summary Get top groups
+// @Description Get groups ranked by song count, most songs first. Groups with the same count are ranked by name.
+// @Produce json
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} GroupRank
+// @Router /groups/top [get]
+func getTopGroups(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > maxTopGroupsLimit {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("limit must be between 1 and %d", maxTopGroupsLimit))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 || offset > cfg.MaxOffset {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("offset must be between 0 and %d", cfg.MaxOffset))
+		return
+	}
+
+	var counts []GroupCount
+	if err := dbFrom(c).Model(&Song{}).
+		Select(`"group", count(*) AS songs`).
+		Group(`"group"`).
+		Order(`songs DESC, "group"`).
+		Limit(limit).Offset(offset).
+		Scan(&counts).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to load groups")
+		return
+	}
+
+	ranks := make([]GroupRank, len(counts))
+	for i, count := range counts {
+		ranks[i] = GroupRank{Rank: offset + i + 1, GroupCount: count}
+	}
+	respondJSON(c, http.StatusOK, ranks)
+}
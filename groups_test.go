@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGroupsCache(t *testing.T) {
+	var gc groupsCache
+	groups := []GroupCount{{Group: "Queen", Songs: 2}}
+
+	_, generation, ok := gc.get()
+	if ok {
+		t.Fatal("get() on an empty cache hit")
+	}
+	gc.set(groups, time.Minute, generation)
+	if got, _, ok := gc.get(); !ok || !reflect.DeepEqual(got, groups) {
+		t.Errorf("get() = %v, %v, want %v, true", got, ok, groups)
+	}
+
+	gc.invalidate()
+	if _, _, ok := gc.get(); ok {
+		t.Error("get() hit after invalidate")
+	}
+
+	gc.set(groups, -time.Second, generation+1)
+	if _, _, ok := gc.get(); ok {
+		t.Error("get() hit after the ttl expired")
+	}
+}
+
+func TestGroupsCacheDropsResultQueriedBeforeInvalidation(t *testing.T) {
+	var gc groupsCache
+	_, generation, _ := gc.get()
+	// A song change commits while the query runs.
+	gc.invalidate()
+	gc.set([]GroupCount{{Group: "Queen", Songs: 1}}, time.Minute, generation)
+	if got, _, ok := gc.get(); ok {
+		t.Errorf("get() = %v, want the stale result dropped", got)
+	}
+}
+
+func TestGroupsCacheInvalidatedBySongChanges(t *testing.T) {
+	t.Cleanup(groupCache.invalidate)
+	tests := []struct {
+		changeType  string
+		invalidated bool
+	}{
+		{songCreated, true},
+		{songRegrouped, true},
+		{songDeleted, true},
+		{songUpdated, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.changeType, func(t *testing.T) {
+			_, generation, _ := groupCache.get()
+			groupCache.set([]GroupCount{{Group: "Queen", Songs: 1}}, time.Minute, generation)
+			onSongChanged(Song{ID: 1}, tt.changeType)
+			if _, _, ok := groupCache.get(); ok == tt.invalidated {
+				t.Errorf("cache hit = %v after a %s change, want %v", ok, tt.changeType, !tt.invalidated)
+			}
+		})
+	}
+}
+
+func TestGetGroupsServesFromCache(t *testing.T) {
+	t.Cleanup(groupCache.invalidate)
+	groups := []GroupCount{{Group: "Queen", Songs: 2}, {Group: "Yes", Songs: 1}}
+	_, generation, _ := groupCache.get()
+	groupCache.set(groups, time.Minute, generation)
+
+	// The router has no database, so a cache miss would fail the request.
+	r := gin.New()
+	r.GET("/groups", getGroups)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/groups", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got []GroupCount
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, groups) {
+		t.Errorf("groups = %v, want %v", got, groups)
+	}
+}
+
+func TestGetTopGroupsRejectsInvalidParams(t *testing.T) {
+	previous := cfg
+	cfg.MaxOffset = 1000
+	t.Cleanup(func() { cfg = previous })
+	r := gin.New()
+	r.GET("/groups/top", getTopGroups)
+	for _, query := range []string{"limit=0", "limit=101", "limit=x", "offset=-1", "offset=1001", "offset=x"} {
+		t.Run(query, func(t *testing.T) {
+			if w := serve(r, "/groups/top?"+query); w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestGetTopGroups(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Yes", Song: "Roundabout"},
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Queen", Song: "Bohemian Rhapsody"},
+		{Group: "ABBA", Song: "Waterloo"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/groups/top", getTopGroups)
+
+	tests := []struct {
+		query string
+		want  []GroupRank
+	}{
+		{"", []GroupRank{
+			{1, GroupCount{Group: "Queen", Songs: 2}},
+			{2, GroupCount{Group: "ABBA", Songs: 1}},
+			{3, GroupCount{Group: "Yes", Songs: 1}},
+		}},
+		{"?limit=1&offset=1", []GroupRank{{2, GroupCount{Group: "ABBA", Songs: 1}}}},
+		{"?offset=3", []GroupRank{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(r, "/groups/top"+tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var got []GroupRank
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("top groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxTopWords = 50
+
+// stopwords are left out of the most common words of a group.
+var stopwords = []string{
+	"a", "about", "all", "am", "an", "and", "are", "as", "at", "be", "but", "by",
+	"can", "do", "don't", "for", "from", "have", "he", "her", "his", "i", "i'm",
+	"if", "in", "is", "it", "it's", "just", "me", "my", "no", "not", "of", "oh",
+	"on", "or", "our", "she", "so", "that", "the", "their", "them", "then",
+	"there", "they", "this", "to", "up", "was", "we", "what", "when", "will",
+	"with", "you", "you're", "your",
+}
+
+// verseCountSQL counts the verses of text like splitVerses does: blocks of
+// lines separated by lines that are empty or whitespace.
+const verseCountSQL = `CASE WHEN btrim(text, E' \t\r\n') = '' THEN 0
+	ELSE array_length(regexp_split_to_array(btrim(text, E' \t\r\n'), E'\\n[ \\t\\r]*(\\n[ \\t\\r]*)+'), 1) END`
+
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int64  `json:"count"`
+}
+
+type GroupStats struct {
+	Group             string      `json:"group"`
+	Songs             int64       `json:"songs"`
+	Verses            int64       `json:"verses"`
+	AverageTextLength float64     `json:"average_text_length"`
+	EarliestRelease   *string     `json:"earliest_release"`
+	LatestRelease     *string     `json:"latest_release"`
+	TopWords          []WordCount `json:"top_words"`
+}
+
+// @Summary Get group statistics
+// @Description Get aggregate lyric statistics for a group: songs, verses, average lyric length in characters, release date range and the most common words without stopwords
+// @Produce json
+// @Param group path string true "Group Name"
+// @Param words query int false "Number of top words (default 10, max 50)"
+// @Success 200 {object} GroupStats
+// @Failure 404 {object} APIError
+// @Router /groups/{group}/stats [get]
+func getGroupStats(c *gin.Context) {
+	words, err := strconv.Atoi(c.DefaultQuery("words", "10"))
+	if err != nil || words < 0 || words > maxTopWords {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("words must be between 0 and %d", maxTopWords))
+		return
+	}
+
+	stats := GroupStats{Group: c.Param("group"), TopWords: []WordCount{}}
+	var row struct {
+		Songs             int64
+		Verses            int64
+		AverageTextLength sql.NullFloat64
+		EarliestRelease   sql.NullString
+		LatestRelease     sql.NullString
+	}
+	err = dbFrom(c).Model(&Song{}).
+		Select(`count(*) AS songs, coalesce(sum(`+verseCountSQL+`), 0) AS verses,
+			avg(length(text)) AS average_text_length,
+			min(NULLIF(release_date, '')) AS earliest_release, max(NULLIF(release_date, '')) AS latest_release`).
+		Where(`"group" = ?`, stats.Group).
+		Scan(&row).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get group statistics")
+		return
+	}
+	if row.Songs == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, "Group not found")
+		return
+	}
+	stats.Songs = row.Songs
+	stats.Verses = row.Verses
+	stats.AverageTextLength = row.AverageTextLength.Float64
+	if row.EarliestRelease.Valid {
+		stats.EarliestRelease = &row.EarliestRelease.String
+		stats.LatestRelease = &row.LatestRelease.String
+	}
+
+	if words > 0 {
+		err = dbFrom(c).Raw(`SELECT word, count(*) AS count
+			FROM songs, regexp_split_to_table(lower(text), E'[^[:alnum:]\']+') AS word
+			WHERE "group" = ? AND length(word) > 1 AND word NOT IN ?
+			GROUP BY word ORDER BY count DESC, word LIMIT ?`,
+			stats.Group, stopwords, words).Scan(&stats.TopWords).Error
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get group statistics")
+			return
+		}
+	}
+	respondJSON(c, http.StatusOK, stats)
+}
+
+type YearCount struct {
+	Year  int   `json:"year"`
+	Songs int64 `json:"songs"`
+}
+
+// @Summary Get the release years of a group
+// @Description Get the distinct years a group released songs in, oldest first, with the number of songs per year. Songs without a release date are left out.
+// @Produce json
+// @Param group path string true "Group Name"
+// @Success 200 {array} YearCount
+// @Failure 404 {object} APIError
+// @Router /groups/{group}/years [get]
+func getGroupYears(c *gin.Context) {
+	group := c.Param("group")
+	var songs int64
+	if err := dbFrom(c).Model(&Song{}).Where(`"group" = ?`, group).Count(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get group years")
+		return
+	}
+	if songs == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, "Group not found")
+		return
+	}
+
+	// Release dates are stored as YYYY-MM-DD, so the year is the first four
+	// characters.
+	years := []YearCount{}
+	if err := dbFrom(c).Model(&Song{}).
+		Select(`left(release_date, 4)::int AS year, count(*) AS songs`).
+		Where(`"group" = ? AND release_date ~ '^[0-9]{4}-'`, group).
+		Group("year").
+		Order("year").
+		Scan(&years).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get group years")
+		return
+	}
+	respondJSON(c, http.StatusOK, years)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetGroupStatsRejectsInvalidWords(t *testing.T) {
+	r := gin.New()
+	r.GET("/groups/:group/stats", getGroupStats)
+	for _, query := range []string{"words=-1", "words=51", "words=many"} {
+		t.Run(query, func(t *testing.T) {
+			if w := serve(r, "/groups/Queen/stats?"+query); w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestGetGroupStats(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo", ReleaseDate: "1991-02-04", Text: "While the sun hangs in the sky\n\nAnd the desert has sand\n \nSun"},
+		{Group: "Queen", Song: "Bicycle Race", Text: "Bicycle, bicycle, SUN"},
+		{Group: "Yes", Song: "Roundabout", ReleaseDate: "1971-11-26", Text: "sun sun sun"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/groups/:group/stats", getGroupStats)
+
+	w := serve(r, "/groups/Queen/stats?words=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var stats GroupStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Songs != 2 || stats.Verses != 4 {
+		t.Errorf("songs, verses = %d, %d, want 2, 4", stats.Songs, stats.Verses)
+	}
+	if stats.EarliestRelease == nil || *stats.EarliestRelease != "1991-02-04" ||
+		stats.LatestRelease == nil || *stats.LatestRelease != "1991-02-04" {
+		t.Errorf("release range = %v - %v, want 1991-02-04 - 1991-02-04", stats.EarliestRelease, stats.LatestRelease)
+	}
+	if want := []WordCount{{"sun", 3}, {"bicycle", 2}}; !reflect.DeepEqual(stats.TopWords, want) {
+		t.Errorf("top words = %v, want %v", stats.TopWords, want)
+	}
+
+	if w := serve(r, "/groups/ABBA/stats"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown group status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetGroupYears(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo", ReleaseDate: "1991-02-04"},
+		{Group: "Queen", Song: "Bicycle Race", ReleaseDate: "1978-10-13"},
+		{Group: "Queen", Song: "Headlong", ReleaseDate: "1991-05-13"},
+		{Group: "Queen", Song: "Demo"},
+		{Group: "Yes", Song: "Roundabout", ReleaseDate: "1971-11-26"},
+		{Group: "ABBA", Song: "Unreleased"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/groups/:group/years", getGroupYears)
+
+	tests := []struct {
+		group string
+		want  []YearCount
+	}{
+		{"Queen", []YearCount{{1978, 1}, {1991, 2}}},
+		{"ABBA", []YearCount{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.group, func(t *testing.T) {
+			w := serve(r, "/groups/"+tt.group+"/years")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var got []YearCount
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("years = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if w := serve(r, "/groups/Muse/years"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown group status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ID types songs can be addressed by, set with ID_TYPE. Every song has both
+// an integer id and a UUID; with uuid only the UUID is accepted and
+// returned, so the catalog can't be enumerated.
+const (
+	idTypeInt  = "int"
+	idTypeUUID = "uuid"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// whereSongID restricts query to the song identified by id. It returns false
+// if id isn't a valid id for cfg.IDType.
+func whereSongID(query *gorm.DB, id string) (*gorm.DB, bool) {
+	if cfg.IDType == idTypeUUID {
+		if !uuidPattern.MatchString(id) {
+			return query, false
+		}
+		return query.Where("songs.uuid = ?", id), true
+	}
+	n, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return query, false
+	}
+	return query.Where("songs.id = ?", n), true
+}
+
+// idParam parses the path parameter name as a numeric id. It writes a 400
+// response about what and returns false if it isn't one, so the value never
+// reaches a query as anything but a number.
+func idParam(c *gin.Context, name, what string) (uint64, bool) {
+	n, err := strconv.ParseUint(c.Param(name), 10, 0)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid "+what+" ID")
+		return 0, false
+	}
+	return n, true
+}
+
+// findSong loads the song identified by the id path parameter into song
+// using query. It writes the error response and returns false if there is
+// no such song.
+func findSong(c *gin.Context, query *gorm.DB, song *Song) bool {
+	query, ok := whereSongID(query, c.Param("id"))
+	if !ok || query.First(song).Error != nil {
+		respondError(c, http.StatusNotFound, codeNotFound, "Song not found")
+		return false
+	}
+	return true
+}
+
+// SongRef refers to a song in request and response bodies by its public
+// identifier: the id, written as a JSON number, or with ID_TYPE=uuid the
+// UUID. Requests may send either form as a string.
+type SongRef string
+
+func songRef(song Song) SongRef {
+	if cfg.IDType == idTypeUUID {
+		return SongRef(song.UUID)
+	}
+	return SongRef(strconv.FormatUint(uint64(song.ID), 10))
+}
+
+func (r SongRef) MarshalJSON() ([]byte, error) {
+	if n, err := strconv.ParseUint(string(r), 10, 0); err == nil && cfg.IDType != idTypeUUID {
+		return strconv.AppendUint(nil, n, 10), nil
+	}
+	return json.Marshal(string(r))
+}
+
+func (r *SongRef) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*r = SongRef(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return errors.New("song ids must be numbers or strings")
+	}
+	*r = SongRef(n.String())
+	return nil
+}
+
+// normalizeSongRefs returns refs in the form songRef gives them, or false if
+// any of them isn't a valid identifier for cfg.IDType.
+func normalizeSongRefs(refs []SongRef) ([]SongRef, bool) {
+	normalized := make([]SongRef, len(refs))
+	for i, ref := range refs {
+		if cfg.IDType == idTypeUUID {
+			if !uuidPattern.MatchString(string(ref)) {
+				return nil, false
+			}
+			normalized[i] = SongRef(strings.ToLower(string(ref)))
+			continue
+		}
+		n, err := strconv.ParseUint(string(ref), 10, 0)
+		if err != nil {
+			return nil, false
+		}
+		normalized[i] = SongRef(strconv.FormatUint(n, 10))
+	}
+	return normalized, true
+}
+
+// whereSongRefs restricts query to the songs refs identify. The refs must
+// have been normalized.
+func whereSongRefs(query *gorm.DB, refs []SongRef) *gorm.DB {
+	if cfg.IDType == idTypeUUID {
+		uuids := make([]string, len(refs))
+		for i, ref := range refs {
+			uuids[i] = string(ref)
+		}
+		return query.Where("songs.uuid IN ?", uuids)
+	}
+	ids := make([]uint64, len(refs))
+	for i, ref := range refs {
+		ids[i], _ = strconv.ParseUint(string(ref), 10, 0)
+	}
+	return query.Where("songs.id IN ?", ids)
+}
+
+// hideSongIDs drops the integer ids of songs from a response decoded into
+// generic JSON values with ID_TYPE=uuid. Objects describing a song carry its
+// uuid, so their id goes; numeric song_id columns of rows belonging to a
+// song go as well. References meant for clients are SongRefs, which are
+// strings in that mode and stay.
+func hideSongIDs(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if _, ok := v["uuid"]; ok {
+			delete(v, "id")
+		}
+		if _, ok := v["song_id"].(json.Number); ok {
+			delete(v, "song_id")
+		}
+		for _, inner := range v {
+			hideSongIDs(inner)
+		}
+	case []any:
+		for _, inner := range v {
+			hideSongIDs(inner)
+		}
+	}
+	return value
+}
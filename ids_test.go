@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func withIDType(t *testing.T, idType string) {
+	t.Helper()
+	previous := cfg.IDType
+	cfg.IDType = idType
+	t.Cleanup(func() { cfg.IDType = previous })
+}
+
+func TestSongRefJSON(t *testing.T) {
+	song := Song{ID: 42, UUID: "0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00"}
+	tests := []struct {
+		idType string
+		want   string
+	}{
+		{idTypeInt, `42`},
+		{idTypeUUID, `"0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.idType, func(t *testing.T) {
+			withIDType(t, tt.idType)
+			data, err := json.Marshal(songRef(song))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("json.Marshal(songRef) = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSongRefs(t *testing.T) {
+	tests := []struct {
+		name   string
+		idType string
+		body   string
+		want   []SongRef
+		ok     bool
+	}{
+		{"numbers", idTypeInt, `[1, 20]`, []SongRef{"1", "20"}, true},
+		{"numeric strings", idTypeInt, `["007"]`, []SongRef{"7"}, true},
+		{"uuid in int mode", idTypeInt, `["0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00"]`, nil, false},
+		{"negative", idTypeInt, `[-1]`, nil, false},
+		{"uuids are lowercased", idTypeUUID, `["0B6F7C1E-4A3D-4F2A-9A57-3C2A1D9E8F00"]`, []SongRef{"0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00"}, true},
+		{"number in uuid mode", idTypeUUID, `[5]`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withIDType(t, tt.idType)
+			var refs []SongRef
+			if err := json.Unmarshal([]byte(tt.body), &refs); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := normalizeSongRefs(refs)
+			if ok != tt.ok || (ok && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("normalizeSongRefs(%s) = %q, %t, want %q, %t", tt.body, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPublicJSONHidesSongIDs(t *testing.T) {
+	withIDType(t, idTypeUUID)
+	song := Song{
+		ID:    7,
+		UUID:  "0b6f7c1e-4a3d-4f2a-9a57-3c2a1d9e8f00",
+		Group: "Muse",
+		Song:  "Uprising",
+		Links: []SongLink{{ID: 3, SongID: 7, URL: "https://example.com"}},
+	}
+	value, err := publicJSON(gin.H{"song": song, "next": songRef(song)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := value.(map[string]any)
+	body := got["song"].(map[string]any)
+	if _, ok := body["id"]; ok {
+		t.Error("song id was not hidden")
+	}
+	if body["uuid"] != song.UUID {
+		t.Errorf("uuid = %v, want %s", body["uuid"], song.UUID)
+	}
+	link := body["links"].([]any)[0].(map[string]any)
+	if _, ok := link["song_id"]; ok {
+		t.Error("song_id of link was not hidden")
+	}
+	if _, ok := link["id"]; !ok {
+		t.Error("id of link was hidden")
+	}
+	if got["next"] != song.UUID {
+		t.Errorf("next = %v, want %s", got["next"], song.UUID)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// readImportFile returns the songs in a JSON array, or in NDJSON like the
+// output of GET /songs/export.ndjson, as raw JSON objects.
+func readImportFile(r io.Reader) ([]json.RawMessage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	var songs []json.RawMessage
+	if bytes.HasPrefix(data, []byte("[")) {
+		err := json.Unmarshal(data, &songs)
+		return songs, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var song json.RawMessage
+		if err := decoder.Decode(&song); err == io.EOF {
+			return songs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+}
+
+// importSong creates the song in entry through the same checks as
+// POST /songs. Entries that don't decode into a song are rejected like
+// songs failing the checks. Enrichment that times out is finished before
+// returning.
+func importSong(entry json.RawMessage, opts checkOptions) error {
+	var song Song
+	if err := decodeSong(entry, &song); err != nil {
+		return invalidSongError("Invalid input: " + err.Error())
+	}
+	if err := createSong(context.Background(), db, &song, opts); err != nil {
+		return err
+	}
+	if song.EnrichmentPending {
+		finishEnrichment(song.ID)
+	}
+	return nil
+}
+
+// runImport implements `music_library import --file songs.json`: it creates
+// the songs in the file through the same checks as POST /songs and returns
+// the exit code. Songs that are rejected are logged and skipped.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "JSON array or NDJSON file of songs to import")
+	truncate := flags.Bool("truncate", false, "truncate text exceeding the maximum length instead of rejecting the song")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "import: --file is required")
+		flags.Usage()
+		return 2
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		logrus.Errorf("Failed to open %s: %v", *file, err)
+		return 1
+	}
+	defer f.Close()
+	entries, err := readImportFile(f)
+	if err != nil {
+		logrus.Errorf("Failed to read %s: %v", *file, err)
+		return 1
+	}
+
+	setup()
+	opts := checkOptions{truncate: *truncate, warn: func(message string) { logrus.Warn(message) }}
+	imported, failed := 0, 0
+	for i, entry := range entries {
+		err := importSong(entry, opts)
+		var invalid invalidSongError
+		_, conflict := asConflict(err)
+		switch {
+		case errors.As(err, &invalid), conflict:
+			logrus.Warnf("Skipping song %d: %v", i+1, err)
+			failed++
+		case err != nil:
+			logrus.Errorf("Failed to import song %d: %v", i+1, err)
+			failed++
+		default:
+			imported++
+		}
+	}
+
+	logrus.Infof("Imported %d songs, %d failed", imported, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadImportFile(t *testing.T) {
+	want := []string{`{"group":"Queen","song":"Innuendo"}`, `{"group":"Yes","song":"Roundabout"}`}
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"array", "\n [" + want[0] + ",\n" + want[1] + "]\n"},
+		{"ndjson", want[0] + "\n" + want[1] + "\n"},
+		{"ndjson without trailing newline", want[0] + "\n\n" + want[1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := readImportFile(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(want) {
+				t.Fatalf("got %d entries, want %d", len(entries), len(want))
+			}
+			for i, entry := range entries {
+				if strings.TrimSpace(string(entry)) != want[i] {
+					t.Errorf("entry %d = %s, want %s", i, entry, want[i])
+				}
+			}
+		})
+	}
+
+	for _, data := range []string{`[{"group":"Queen"}`, `{"group":"Queen"} {`} {
+		if _, err := readImportFile(strings.NewReader(data)); err == nil {
+			t.Errorf("readImportFile(%q) error = nil, want an error", data)
+		}
+	}
+}
+
+func TestRunImportUsageErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	malformed := filepath.Join(t.TempDir(), "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`[{"group":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, 2},
+		{[]string{"--files", "songs.json"}, 2},
+		{[]string{"--file", missing}, 1},
+		{[]string{"--file", malformed}, 1},
+	}
+	for _, tt := range tests {
+		if got := runImport(tt.args); got != tt.want {
+			t.Errorf("runImport(%q) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestImportSong(t *testing.T) {
+	testDB(t)
+	opts := checkOptions{warn: func(string) {}}
+	if err := importSong(json.RawMessage(`{"group":"Queen","song":"Innuendo","release_date":"04.02.1991"}`), opts); err != nil {
+		t.Fatalf("importSong() error = %v", err)
+	}
+	var song Song
+	if err := db.Take(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	if song.ReleaseDate != "1991-02-04" {
+		t.Errorf("release date = %q, want it normalized to 1991-02-04", song.ReleaseDate)
+	}
+
+	var invalid invalidSongError
+	for _, entry := range []string{`{"group":"Queen","song":"Innuendo","release_date":"someday"}`, `{"group":1}`} {
+		if err := importSong(json.RawMessage(entry), opts); !errors.As(err, &invalid) {
+			t.Errorf("importSong(%s) error = %v, want an invalidSongError", entry, err)
+		}
+	}
+	if err := importSong(json.RawMessage(`{"group":"Queen","song":"Innuendo"}`), opts); err == nil {
+		t.Error("importSong() of a duplicate error = nil, want a conflict")
+	} else if _, ok := asConflict(err); !ok {
+		t.Errorf("importSong() of a duplicate error = %v, want a conflict", err)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxImportSize bounds import files, uploaded or fetched.
+	maxImportSize = 64 << 20
+	// maxImportJobErrors bounds how many rejected songs a job lists.
+	maxImportJobErrors = 100
+	// importProgressInterval is how many songs are imported between progress
+	// updates of a job.
+	importProgressInterval = 100
+	// importFetchTimeout bounds fetching an import file from a URL.
+	importFetchTimeout = time.Minute
+)
+
+// Import job statuses.
+const (
+	importPending   = "pending"
+	importRunning   = "running"
+	importCompleted = "completed"
+	importFailed    = "failed"
+)
+
+// ImportJob tracks an import running in the background.
+type ImportJob struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	Status     string        `json:"status"`
+	Source     string        `json:"source"`
+	Total      int           `json:"total"`
+	Processed  int           `json:"processed"`
+	Imported   int           `json:"imported"`
+	Failed     int           `json:"failed"`
+	Errors     []ImportError `json:"errors" gorm:"serializer:json"`
+	Error      string        `json:"error,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
+
+// ImportError is a song rejected by an import, by its 1-based position in
+// the file.
+type ImportError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+var importClient = publicClient(importFetchTimeout)
+
+// fetchImportFile downloads the import file at u.
+func fetchImportFile(u string) ([]json.RawMessage, error) {
+	resp, err := importClient.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with %s", u, resp.Status)
+	}
+	return readImportFile(http.MaxBytesReader(nil, resp.Body, maxImportSize))
+}
+
+// importJobLock names the job lock held while the import job id runs, which
+// tells other replicas the job isn't interrupted.
+func importJobLock(id uint) string {
+	return "import-job:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// runImportJob imports entries, or the file at source when entries is nil,
+// recording progress on job.
+func runImportJob(job ImportJob, entries []json.RawMessage, opts checkOptions) {
+	fail := func(err error) {
+		now := time.Now()
+		job.Status, job.Error, job.FinishedAt = importFailed, err.Error(), &now
+		db.Save(&job)
+	}
+	lock, ok, err := tryJobLock(importJobLock(job.ID))
+	if err != nil {
+		fail(err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer lock.release()
+	if entries == nil {
+		var err error
+		if entries, err = fetchImportFile(job.Source); err != nil {
+			fail(err)
+			return
+		}
+	}
+	job.Status, job.Total = importRunning, len(entries)
+	if err := db.Save(&job).Error; err != nil {
+		logrus.Errorf("Failed to start import job %d: %v", job.ID, err)
+		return
+	}
+
+	for i, entry := range entries {
+		err := importSong(entry, opts)
+		if err != nil {
+			job.Failed++
+			var invalid invalidSongError
+			message := "Failed to create song"
+			if conflict, ok := asConflict(err); ok {
+				message = conflict.Error()
+			} else if errors.As(err, &invalid) {
+				message = err.Error()
+			}
+			if len(job.Errors) < maxImportJobErrors {
+				job.Errors = append(job.Errors, ImportError{Index: i + 1, Error: message})
+			}
+		} else {
+			job.Imported++
+		}
+		job.Processed++
+		if job.Processed%importProgressInterval == 0 {
+			if err := db.Save(&job).Error; err != nil {
+				logrus.Errorf("Failed to record progress of import job %d: %v", job.ID, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	job.Status, job.FinishedAt = importCompleted, &now
+	if err := db.Save(&job).Error; err != nil {
+		logrus.Errorf("Failed to complete import job %d: %v", job.ID, err)
+	}
+	logrus.Infof("Import job %d imported %d songs, %d failed", job.ID, job.Imported, job.Failed)
+}
+
+// failInterruptedImportJobs marks jobs that were running when their server
+// stopped as failed, as nothing will resume them. Jobs whose lock is held
+// are still running on another replica and are left alone.
+func failInterruptedImportJobs() {
+	unfinished := []string{importPending, importRunning}
+	var jobs []ImportJob
+	if err := db.Select("id").Where("status IN ?", unfinished).Find(&jobs).Error; err != nil {
+		logrus.Errorf("Failed to fail interrupted import jobs: %v", err)
+		return
+	}
+	var failed int64
+	for _, job := range jobs {
+		lock, ok, err := tryJobLock(importJobLock(job.ID))
+		if err != nil {
+			logrus.Errorf("Failed to fail interrupted import job %d: %v", job.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		result := db.Model(&job).Where("status IN ?", unfinished).
+			Updates(map[string]any{"status": importFailed, "error": "Interrupted by a restart", "finished_at": time.Now()})
+		lock.release()
+		if result.Error != nil {
+			logrus.Errorf("Failed to fail interrupted import job %d: %v", job.ID, result.Error)
+		}
+		failed += result.RowsAffected
+	}
+	if failed > 0 {
+		logrus.Warnf("Marked %d interrupted import jobs as failed", failed)
+	}
+}
+
+// @Summary Start an import job
+// @Description Import songs in the background from a JSON array or NDJSON body, or from the file at ?url=. Songs go through the same checks as POST /songs; rejected ones are listed on the job and skipped.
+// @Accept json
+// @Produce json
+// @Param url query string false "http(s) URL of the file to import instead of the body"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting the song"
+// @Success 202 {object} ImportJob
+// @Router /songs/import/jobs [post]
+func startImportJob(c *gin.Context) {
+	job := ImportJob{Status: importPending, Source: "upload", Errors: []ImportError{}}
+	var entries []json.RawMessage
+	if source := c.Query("url"); source != "" {
+		if u, err := url.Parse(source); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid url, expected an http(s) URL")
+			return
+		}
+		job.Source = source
+	} else {
+		var err error
+		entries, err = readImportFile(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, codeMalformedJSON, "Expected a JSON array or NDJSON of songs")
+			return
+		}
+		if entries == nil {
+			entries = []json.RawMessage{}
+		}
+		job.Total = len(entries)
+	}
+	if err := dbFrom(c).Create(&job).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to create import job")
+		return
+	}
+
+	opts := checkOptions{truncate: c.Query("truncate") == "true", warn: func(string) {}}
+	go runImportJob(job, entries, opts)
+	c.Header("Location", "/songs/import/jobs/"+strconv.FormatUint(uint64(job.ID), 10))
+	respondJSON(c, http.StatusAccepted, job)
+}
+
+// @Summary Get an import job
+// @Description Get the progress of an import job, and its outcome once finished
+// @Produce json
+// @Param id path int true "Import job ID"
+// @Success 200 {object} ImportJob
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Router /songs/import/jobs/{id} [get]
+func getImportJob(c *gin.Context) {
+	id, ok := idParam(c, "id", "import job")
+	if !ok {
+		return
+	}
+	var job ImportJob
+	if err := dbFrom(c).Where("id = ?", id).First(&job).Error; err != nil {
+		respondError(c, http.StatusNotFound, codeNotFound, "Import job not found")
+		return
+	}
+	respondJSON(c, http.StatusOK, job)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetImportJobRejectsNonNumericID(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/import/jobs/:id", getImportJob)
+
+	for _, id := range []string{"1%20OR%201=1", "status='done'", "abc"} {
+		t.Run(id, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/import/jobs/"+id, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// songIncludes maps the relations ?include= accepts to how they are
+// preloaded. Links are always included.
+var songIncludes = map[string]func(*gorm.DB) *gorm.DB{
+	"links": func(q *gorm.DB) *gorm.DB { return q },
+	"revisions": func(q *gorm.DB) *gorm.DB {
+		return q.Preload("Revisions", func(q *gorm.DB) *gorm.DB { return q.Order("id DESC") })
+	},
+	"translations": func(q *gorm.DB) *gorm.DB {
+		return q.Preload("Lyrics", func(q *gorm.DB) *gorm.DB { return q.Order("lang") })
+	},
+}
+
+// SongWithIncludes is a song with the relations requested with ?include=.
+type SongWithIncludes struct {
+	SongWithStats
+	Revisions    []SongRevision `json:"revisions,omitempty"`
+	Translations []SongLyrics   `json:"translations,omitempty"`
+}
+
+// includeRelations preloads the relations listed in ?include= on query. It
+// writes the error response and returns false for unknown relations.
+func includeRelations(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	include := c.Query("include")
+	if include == "" {
+		return query, true
+	}
+	for _, name := range strings.Split(include, ",") {
+		preload, ok := songIncludes[strings.TrimSpace(name)]
+		if !ok {
+			respondError(c, http.StatusBadRequest, codeInvalidInput,
+				fmt.Sprintf("Unknown include %q, expected links, revisions or translations", strings.TrimSpace(name)))
+			return nil, false
+		}
+		query = preload(query)
+	}
+	return query, true
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIncludeRelationsRejectsUnknownRelations(t *testing.T) {
+	for _, include := range []string{"lyrics", ",", "links,comments"} {
+		t.Run(include, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs/1?include="+include, nil)
+			// No database is needed: the links preload leaves the query as is.
+			query, ok := includeRelations(c, db)
+			if ok || query != nil {
+				t.Fatalf("includeRelations() = %v, %v, want nil, false", query, ok)
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestGetSongIncludes(t *testing.T) {
+	testDB(t)
+	song := Song{Group: "Queen", Song: "Innuendo", Text: "While the sun hangs in the sky"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := saveLyrics(db, &SongLyrics{SongID: song.ID, Lang: "de", Text: "Während die Sonne am Himmel hängt"}); err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/songs/:id", getSong)
+
+	tests := []struct {
+		include          string
+		wantTranslations int
+	}{
+		{"", 0},
+		{"links", 0},
+		{"translations", 2},
+		{"links, translations", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.include, func(t *testing.T) {
+			w := serve(r, "/songs/1?include="+strings.ReplaceAll(tt.include, " ", "%20"))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var got SongWithIncludes
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Translations) != tt.wantTranslations {
+				t.Errorf("translations = %+v, want %d", got.Translations, tt.wantTranslations)
+			}
+		})
+	}
+}
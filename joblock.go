@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jobLock is a PostgreSQL advisory lock held while a background job runs, so
+// that of the replicas sharing the database only one runs the job at a time.
+// Advisory locks belong to a session, so the lock keeps a connection out of
+// the pool until it is released. If the process dies, PostgreSQL closes the
+// session and the lock goes with it.
+type jobLock struct {
+	conn *sql.Conn
+	name string
+}
+
+// tryJobLock takes the lock named name without waiting. It returns false if
+// another runner, in this process or another replica, holds it.
+func tryJobLock(name string) (*jobLock, bool, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&locked); err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+	return &jobLock{conn: conn, name: name}, true, nil
+}
+
+// release unlocks the lock and returns its connection to the pool. When the
+// unlock fails the connection is discarded instead, which ends the session
+// and so releases the lock as well.
+func (l *jobLock) release() {
+	if _, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", l.name); err != nil {
+		logrus.Warnf("Failed to release job lock %s, dropping its connection: %v", l.name, err)
+		l.conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	l.conn.Close()
+}
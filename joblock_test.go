@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestJobLockSecondRunnerSkips(t *testing.T) {
+	testDB(t)
+
+	first, ok, err := tryJobLock("test-job")
+	if err != nil || !ok {
+		t.Fatalf("first tryJobLock() = %v, %v, want the lock", ok, err)
+	}
+	if _, ok, err := tryJobLock("test-job"); err != nil || ok {
+		t.Fatalf("second tryJobLock() = %v, %v, want it to back off", ok, err)
+	}
+	other, ok, err := tryJobLock("other-job")
+	if err != nil || !ok {
+		t.Fatalf("tryJobLock() of another job = %v, %v, want the lock", ok, err)
+	}
+	other.release()
+
+	first.release()
+	again, ok, err := tryJobLock("test-job")
+	if err != nil || !ok {
+		t.Fatalf("tryJobLock() after release = %v, %v, want the lock", ok, err)
+	}
+	again.release()
+}
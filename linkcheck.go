@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Link statuses recorded by the link checker.
+const (
+	linkOK      = "ok"
+	linkBroken  = "broken"
+	linkUnknown = "unknown"
+)
+
+var linkStatuses = map[string]bool{linkOK: true, linkBroken: true, linkUnknown: true}
+
+// checkingLinks is set while a link check runs, so only one runs at a time.
+var checkingLinks atomic.Bool
+
+// checkLink requests link and classifies the response. Links that are gone
+// or point nowhere are broken; timeouts and server errors may be temporary,
+// so they are unknown. Servers that don't support HEAD are retried with GET.
+func checkLink(client *http.Client, link string) string {
+	status, err := requestLink(client, http.MethodHead, link)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = requestLink(client, http.MethodGet, link)
+	}
+
+	var netErr interface{ Timeout() bool }
+	switch {
+	case err != nil && errors.As(err, &netErr) && netErr.Timeout():
+		return linkUnknown
+	case err != nil:
+		return linkBroken
+	case status < 400:
+		return linkOK
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return linkBroken
+	default:
+		return linkUnknown
+	}
+}
+
+func requestLink(client *http.Client, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// checkLinks checks the links of songs with cfg.LinkCheckConcurrency
+// requests at a time and records the result on each song. The check isn't
+// an edit, so updated_at and thereby the ETag are left alone.
+func checkLinks(songs []Song) {
+	defer checkingLinks.Store(false)
+
+	client := publicClient(cfg.LinkCheckTimeout)
+	sem := make(chan struct{}, cfg.LinkCheckConcurrency)
+	var wg sync.WaitGroup
+	var broken atomic.Int64
+	for _, song := range songs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			status := checkLink(client, song.Link)
+			if status == linkBroken {
+				broken.Add(1)
+			}
+			// The link may have been changed while it was checked.
+			err := db.Model(&Song{}).Where("id = ? AND link = ?", song.ID, song.Link).UpdateColumns(map[string]any{
+				"link_status":     status,
+				"link_checked_at": time.Now(),
+			}).Error
+			if err != nil {
+				logrus.Errorf("Failed to record link status of song %d: %v", song.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	logrus.Infof("Checked %d links, %d broken", len(songs), broken.Load())
+}
+
+// @Summary Check song links
+// @Description Start checking in the background whether the link of every song still resolves. Results are recorded as link_status and link_checked_at on each song.
+// @Produce json
+// @Success 202 {object} map[string]int
+// @Failure 409 {object} APIError
+// @Router /admin/check-links [post]
+func startLinkCheck(c *gin.Context) {
+	if !checkingLinks.CompareAndSwap(false, true) {
+		respondError(c, http.StatusConflict, codeConflict, "A link check is already running")
+		return
+	}
+	// Other replicas may be running one as well.
+	lock, ok, err := tryJobLock("check-links")
+	if err != nil || !ok {
+		checkingLinks.Store(false)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to start link check")
+		} else {
+			respondError(c, http.StatusConflict, codeConflict, "A link check is already running")
+		}
+		return
+	}
+
+	var songs []Song
+	if err := dbFrom(c).Select("id", "link").Where("link ~* ?", validLinkPattern).Find(&songs).Error; err != nil {
+		lock.release()
+		checkingLinks.Store(false)
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get songs")
+		return
+	}
+	go func() {
+		defer lock.release()
+		checkLinks(songs)
+	}()
+	respondJSON(c, http.StatusAccepted, gin.H{"songs": len(songs)})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SongLink struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SongID    uint      `json:"song_id" gorm:"index;not null"`
+	Type      string    `json:"type"`
+	URL       string    `json:"url" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// linkTypesByHost maps well-known hosts to link types.
+var linkTypesByHost = map[string]string{
+	"youtube.com":       "youtube",
+	"youtu.be":          "youtube",
+	"music.youtube.com": "youtube_music",
+	"open.spotify.com":  "spotify",
+	"spotify.com":       "spotify",
+	"music.apple.com":   "apple_music",
+	"soundcloud.com":    "soundcloud",
+	"deezer.com":        "deezer",
+}
+
+// detectLinkType guesses the platform of a link from its host.
+func detectLinkType(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "other"
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if linkType, ok := linkTypesByHost[host]; ok {
+		return linkType
+	}
+	return "other"
+}
+
+func validLinkURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// The link column of a song is a projection of its primary link, the first
+// of its song_links rows. setPrimaryLink and projectLink are the only
+// places that write it, so the two never diverge.
+
+// setPrimaryLink makes url the primary link of song, replacing the URL of
+// its first link, adding a link if it has none, or removing the first link
+// if url is empty, and projects the result onto song.
+func setPrimaryLink(tx *gorm.DB, song *Song, url string) error {
+	var primary SongLink
+	err := tx.Where("song_id = ?", song.ID).Order("id").Take(&primary).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if url != "" {
+			err = tx.Create(&SongLink{SongID: song.ID, Type: detectLinkType(url), URL: url}).Error
+		} else {
+			err = nil
+		}
+	case err != nil:
+	case url == "":
+		err = tx.Delete(&primary).Error
+	case url != primary.URL:
+		err = tx.Model(&primary).Updates(map[string]any{"url": url, "type": detectLinkType(url)}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return projectLink(tx, song)
+}
+
+// projectLink loads the links of song into song.Links and writes the URL of
+// the primary one to its link column and to song.Link, where song.Link holds
+// the stored value. A changed link drops the result of its last check.
+func projectLink(tx *gorm.DB, song *Song) error {
+	if err := tx.Where("song_id = ?", song.ID).Order("id").Find(&song.Links).Error; err != nil {
+		return err
+	}
+	link := ""
+	if len(song.Links) > 0 {
+		link = song.Links[0].URL
+	}
+	if link == song.Link {
+		return nil
+	}
+	song.Link, song.LinkStatus, song.LinkCheckedAt = link, "", nil
+	return tx.Model(&Song{}).Where("id = ?", song.ID).
+		UpdateColumns(map[string]any{"link": link, "link_status": "", "link_checked_at": nil}).Error
+}
+
+// backfillSongLinks copies the single link of songs that predate SongLink
+// into the song_links table.
+func backfillSongLinks() {
+	var songs []Song
+	result := db.Where("link <> '' AND NOT EXISTS (SELECT 1 FROM song_links WHERE song_links.song_id = songs.id)").
+		FindInBatches(&songs, 500, func(tx *gorm.DB, batch int) error {
+			links := make([]SongLink, len(songs))
+			for i, song := range songs {
+				links[i] = SongLink{SongID: song.ID, Type: detectLinkType(song.Link), URL: song.Link}
+			}
+			return db.Create(&links).Error
+		})
+	if result.Error != nil {
+		logrus.Errorf("Failed to backfill song links: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		logrus.Infof("Backfilled links for %d songs", result.RowsAffected)
+	}
+}
+
+// @Summary Add a link to a song
+// @Description Add a typed link (YouTube, Spotify, ...) to a song. The type is detected from the URL when omitted.
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param link body SongLink true "Link"
+// @Success 201 {object} SongLink
+// @Router /songs/{id}/links [post]
+func addSongLink(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+
+	var link SongLink
+	if err := c.ShouldBindJSON(&link); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if !validLinkURL(link.URL) {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid url, expected an http(s) URL")
+		return
+	}
+	link.ID = 0
+	link.SongID = song.ID
+	if link.Type == "" {
+		link.Type = detectLinkType(link.URL)
+	}
+
+	err := dbFrom(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+		return projectLink(tx, &song)
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to add link")
+		return
+	}
+	onSongChanged(song, songUpdated)
+	respondJSON(c, http.StatusCreated, link)
+}
+
+// @Summary Remove a link from a song
+// @Description Remove a link by ID from a song. Removing the first link makes the next one the song's link.
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param linkID path int true "Link ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Router /songs/{id}/links/{linkID} [delete]
+func deleteSongLink(c *gin.Context) {
+	linkID, ok := idParam(c, "linkID", "link")
+	if !ok {
+		return
+	}
+	var song Song
+	if !findSong(c, dbFrom(c).Select("id", "uuid", "link"), &song) {
+		return
+	}
+
+	err := dbFrom(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND song_id = ?", linkID, song.ID).Delete(&SongLink{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return projectLink(tx, &song)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, codeNotFound, "Link not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to delete link")
+		return
+	}
+	onSongChanged(song, songUpdated)
+	respondJSON(c, http.StatusOK, gin.H{"message": "Link deleted"})
+}
+
+// addDefaultLink gives a song that only has the single link field a matching
+// SongLink, as it would be stored. Stored songs get theirs from
+// setPrimaryLink.
+func addDefaultLink(song *Song) {
+	if song.Link != "" && len(song.Links) == 0 {
+		song.Links = []SongLink{{Type: detectLinkType(song.Link), URL: song.Link}}
+	}
+}
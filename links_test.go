@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeleteSongLinkRejectsNonNumericID(t *testing.T) {
+	r := gin.New()
+	r.DELETE("/songs/:id/links/:linkID", deleteSongLink)
+
+	for _, linkID := range []string{"true)%20OR%20(true", "1;DROP%20TABLE%20song_links", "-1", "abc", "1.5"} {
+		t.Run(linkID, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/songs/1/links/"+linkID, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
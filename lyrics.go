@@ -0,0 +1,504 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// wordsPerMinute is the reading speed used to estimate reading time.
+const wordsPerMinute = 200
+
+type LyricStats struct {
+	WordCount          int `json:"word_count"`
+	LineCount          int `json:"line_count"`
+	ReadingTimeSeconds int `json:"reading_time_seconds"`
+}
+
+// hasTextSQL matches songs whose text has anything besides the whitespace
+// splitVerses skips, so they have at least one verse.
+const hasTextSQL = `btrim(text, E' \t\r\n') <> ''`
+
+// splitVerses splits text into verses separated by one or more blank lines.
+func splitVerses(text string) []string {
+	var verses []string
+	var current []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				verses = append(verses, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		verses = append(verses, strings.Join(current, "\n"))
+	}
+	return verses
+}
+
+func lyricStats(text string) LyricStats {
+	var stats LyricStats
+	for _, line := range strings.Split(text, "\n") {
+		words := len(strings.Fields(line))
+		if words == 0 {
+			continue
+		}
+		stats.LineCount++
+		stats.WordCount += words
+	}
+	stats.ReadingTimeSeconds = (stats.WordCount*60 + wordsPerMinute - 1) / wordsPerMinute
+	return stats
+}
+
+const truncatedMarker = " [truncated]"
+
+// limitText enforces cfg.MaxTextLength on text. When truncate is set, text
+// that is too long is cut down and marked instead of being rejected.
+func limitText(text string, truncate bool) (string, bool) {
+	if utf8.RuneCountInString(text) <= cfg.MaxTextLength {
+		return text, true
+	}
+	if !truncate {
+		return text, false
+	}
+	keep := cfg.MaxTextLength - utf8.RuneCountInString(truncatedMarker)
+	return truncateRunes(text, keep) + truncatedMarker, true
+}
+
+// pageVerses sets the lyrics in result to the page of verses requested with
+// page and per_page. Without pagination the verses are capped at
+// cfg.MaxVerses, so a pathological medley doesn't produce a huge response.
+func pageVerses(c *gin.Context, verses []string, result gin.H) bool {
+	pageStr, perPageStr := c.Query("page"), c.Query("per_page")
+	if pageStr == "" && perPageStr == "" {
+		if len(verses) > cfg.MaxVerses {
+			verses = verses[:cfg.MaxVerses]
+			result["truncated"] = true
+			result["hint"] = "Use page and per_page to get the remaining verses"
+		}
+		result["lyrics"] = verses
+		return true
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid page parameter")
+		return false
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(min(10, cfg.MaxVerses))))
+	if err != nil || perPage < 1 || perPage > cfg.MaxVerses {
+		respondError(c, http.StatusBadRequest, codeInvalidInput,
+			"per_page must be between 1 and "+strconv.Itoa(cfg.MaxVerses))
+		return false
+	}
+
+	start := len(verses)
+	if page-1 <= len(verses)/perPage {
+		start = min((page-1)*perPage, len(verses))
+	}
+	end := min(start+perPage, len(verses))
+	result["lyrics"] = verses[start:end]
+	result["page"] = page
+	result["per_page"] = perPage
+	return true
+}
+
+type RandomVerse struct {
+	SongID SongRef `json:"song_id" swaggertype:"string"`
+	Group  string  `json:"group"`
+	Song   string  `json:"song"`
+	Verse  string  `json:"verse"`
+}
+
+// verseRand returns the random source for picking a verse. A seed makes the
+// choice deterministic, e.g. seeding with the date gives a verse of the day.
+func verseRand(seed string) *rand.Rand {
+	if seed == "" {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// @Summary Get a random verse
+// @Description Get a random verse from a random song with lyrics
+// @Produce json
+// @Param seed query string false "Seed for deterministic selection"
+// @Success 200 {object} RandomVerse
+// @Router /verses/random [get]
+func getRandomVerse(c *gin.Context) {
+	rng := verseRand(c.Query("seed"))
+	query := dbFrom(c).Model(&Song{}).Where(hasTextSQL).Session(&gorm.Session{})
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil || count == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, "No lyrics available")
+		return
+	}
+
+	var song Song
+	if err := query.Order("id").Offset(rng.Intn(int(count))).Limit(1).Take(&song).Error; err != nil {
+		respondError(c, http.StatusNotFound, codeNotFound, "No lyrics available")
+		return
+	}
+
+	verses := splitVerses(song.Text)
+	if len(verses) == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, "No lyrics available")
+		return
+	}
+	respondJSON(c, http.StatusOK, RandomVerse{
+		SongID: songRef(song),
+		Group:  song.Group,
+		Song:   song.Song,
+		Verse:  verses[rng.Intn(len(verses))],
+	})
+}
+
+type VerseContext struct {
+	Verse      int     `json:"verse"`
+	VerseCount int     `json:"verse_count"`
+	Text       string  `json:"text"`
+	Previous   *string `json:"previous"`
+	Next       *string `json:"next"`
+}
+
+// respondVerse writes the 1-based verse of verses along with the verses
+// around it. Verse numbers only depend on the text, so a link to a verse
+// stays valid until the lyrics are edited.
+func respondVerse(c *gin.Context, verses []string, verse string) {
+	n, err := strconv.Atoi(verse)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid verse parameter")
+		return
+	}
+	if n < 1 || n > len(verses) {
+		respondError(c, http.StatusNotFound, codeNotFound, "Verse not found")
+		return
+	}
+
+	result := VerseContext{Verse: n, VerseCount: len(verses), Text: verses[n-1]}
+	if n > 1 {
+		result.Previous = &verses[n-2]
+	}
+	if n < len(verses) {
+		result.Next = &verses[n]
+	}
+	respondJSON(c, http.StatusOK, result)
+}
+
+// normalizeLyrics rewrites text in canonical form: lines are trimmed and
+// verses are separated by exactly one blank line.
+func normalizeLyrics(text string) string {
+	verses := splitVerses(text)
+	for i, verse := range verses {
+		lines := strings.Split(verse, "\n")
+		for j := range lines {
+			lines[j] = strings.TrimSpace(lines[j])
+		}
+		verses[i] = strings.Join(lines, "\n")
+	}
+	return strings.Join(verses, "\n\n")
+}
+
+type NormalizeResult struct {
+	Changed    bool   `json:"changed"`
+	VerseCount int    `json:"verse_count"`
+	Text       string `json:"text"`
+}
+
+// @Summary Normalize song lyrics
+// @Description Rewrite the stored lyrics with trimmed lines and a single blank line between verses
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param If-Match header string true "ETag the update is conditional on"
+// @Success 200 {object} NormalizeResult
+// @Failure 412 {object} APIError
+// @Failure 428 {object} APIError
+// @Router /songs/{id}/normalize-lyrics [post]
+func normalizeSongLyrics(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+	if !checkIfMatch(c, song) {
+		return
+	}
+
+	normalized := normalizeLyrics(song.Text)
+	result := NormalizeResult{
+		Changed:    normalized != song.Text,
+		VerseCount: len(splitVerses(normalized)),
+		Text:       normalized,
+	}
+	if result.Changed {
+		previous := song
+		err := dbFrom(c).Transaction(func(tx *gorm.DB) error {
+			if err := saveRevisions(tx, []Song{previous}, actorFrom(c), actionUpdate); err != nil {
+				return err
+			}
+			song.Text = normalized
+			result := tx.Model(&song).Where("updated_at = ?", previous.UpdatedAt).
+				Select(append([]string{"text"}, lyricStatsColumns...)).Updates(&song)
+			if result.Error == nil && result.RowsAffected == 0 {
+				return errSongModified
+			}
+			return result.Error
+		})
+		if errors.Is(err, errSongModified) {
+			respondError(c, http.StatusPreconditionFailed, codePreconditionFailed, "Song has been modified since it was fetched")
+			return
+		}
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to save normalized lyrics")
+			return
+		}
+		c.Header("ETag", songETag(song))
+		onSongChanged(song, songUpdated)
+	}
+	respondJSON(c, http.StatusOK, result)
+}
+
+const (
+	// previewLength is the most characters a lyrics preview has.
+	previewLength = 200
+	// previewScanLength is how much of the text is loaded to find the first
+	// verse in.
+	previewScanLength = 1000
+)
+
+// SongPreview is a song in a listing with a preview in place of its lyrics.
+type SongPreview struct {
+	Song
+	Text    string `json:"text,omitempty"`
+	Preview string `json:"preview"`
+}
+
+// lyricsPreview returns the first verse of text, cut down to previewLength
+// characters.
+func lyricsPreview(text string) string {
+	verses := splitVerses(text)
+	if len(verses) == 0 {
+		return ""
+	}
+	preview := truncateRunes(verses[0], previewLength)
+	if len(preview) < len(verses[0]) {
+		preview = strings.TrimRight(preview, " \t\n") + "…"
+	}
+	return preview
+}
+
+// songPreviews pairs songs, loaded without their text, with previews read
+// from the start of their text.
+func songPreviews(tx *gorm.DB, songs []Song) ([]SongPreview, error) {
+	previews := make([]SongPreview, len(songs))
+	if len(songs) == 0 {
+		return previews, nil
+	}
+	ids := make([]uint, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	var heads []struct {
+		ID   uint
+		Head string
+	}
+	if err := tx.Model(&Song{}).Select("id, left(text, ?) AS head", previewScanLength).
+		Where("id IN ?", ids).Scan(&heads).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]string, len(heads))
+	for _, head := range heads {
+		byID[head.ID] = head.Head
+	}
+	for i, song := range songs {
+		previews[i] = SongPreview{Song: song, Preview: lyricsPreview(byID[song.ID])}
+	}
+	return previews, nil
+}
+
+type LyricsUpdate struct {
+	Text *string `json:"text" binding:"required"`
+}
+
+// lyricsChecks are the songChecks that look at the text.
+var lyricsChecks = []func(*Song, checkOptions) error{
+	checkTextLength,
+	checkProfanity,
+}
+
+// @Summary Replace song lyrics
+// @Description Replace only the lyrics of a song. The text is normalized like POST /songs/{id}/normalize-lyrics and checked like on a full update.
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param lyrics body LyricsUpdate true "New lyrics"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
+// @Param X-Actor header string false "Who is making the change, recorded in the song history"
+// @Param If-Match header string true "ETag the update is conditional on"
+// @Success 200 {object} Song
+// @Failure 412 {object} APIError
+// @Failure 428 {object} APIError
+// @Router /songs/{id}/lyrics [put]
+// @Router /songs/{id}/lyrics [patch]
+func putSongLyrics(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+	if !checkIfMatch(c, song) {
+		return
+	}
+	var update LyricsUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	previous := song
+	song.Text = normalizeLyrics(*update.Text)
+	opts := requestCheckOptions(c)
+	for _, check := range lyricsChecks {
+		if err := check(&song, opts); err != nil {
+			respondError(c, http.StatusBadRequest, codeInvalidInput, err.Error())
+			return
+		}
+	}
+	err := dbFrom(c).Transaction(func(tx *gorm.DB) error {
+		if err := saveRevisions(tx, []Song{previous}, actorFrom(c), actionUpdate); err != nil {
+			return err
+		}
+		columns := append([]string{"text", "flagged"}, lyricStatsColumns...)
+		result := tx.Model(&song).Where("updated_at = ?", previous.UpdatedAt).Select(columns).Updates(&song)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return errSongModified
+		}
+		return result.Error
+	})
+	if errors.Is(err, errSongModified) {
+		respondError(c, http.StatusPreconditionFailed, codePreconditionFailed, "Song has been modified since it was fetched")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to update lyrics")
+		return
+	}
+	c.Header("ETag", songETag(song))
+	onSongChanged(song, songUpdated)
+	localizeSong(c, &song)
+	respondJSON(c, http.StatusOK, song)
+}
+
+type FullLyrics struct {
+	Raw        string   `json:"raw"`
+	Verses     []string `json:"verses"`
+	VerseCount int      `json:"verse_count"`
+	LineCount  int      `json:"line_count"`
+}
+
+// @Summary Get raw and parsed lyrics
+// @Description Get the lyrics exactly as stored together with the verses they parse into
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param lang query string false "Language of the lyrics, defaults to the primary lyrics"
+// @Success 200 {object} FullLyrics
+// @Failure 404 {object} APIError
+// @Router /songs/{id}/lyrics/full [get]
+func getFullLyrics(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+	text, ok := lyricsText(c, song, c.Query("lang"))
+	if !ok {
+		return
+	}
+
+	verses := splitVerses(text)
+	if verses == nil {
+		verses = []string{}
+	}
+	respondJSON(c, http.StatusOK, FullLyrics{
+		Raw:        text,
+		Verses:     verses,
+		VerseCount: len(verses),
+		LineCount:  lyricStats(text).LineCount,
+	})
+}
+
+type LyricsBatchRequest struct {
+	IDs []SongRef `json:"ids" binding:"required" swaggertype:"array,string"`
+}
+
+type BatchLyrics struct {
+	Text   string   `json:"text"`
+	Verses []string `json:"verses"`
+}
+
+type LyricsBatch struct {
+	Lyrics  map[SongRef]BatchLyrics `json:"lyrics"`
+	Missing []SongRef               `json:"missing" swaggertype:"array,string"`
+}
+
+// @Summary Get lyrics of several songs
+// @Description Get the lyrics of up to MAX_LYRICS_BATCH songs by ID, or UUID with ID_TYPE=uuid, raw and split into verses. Lyrics are keyed by the identifier, and identifiers without a song are listed as missing.
+// @Accept json
+// @Produce json
+// @Param request body LyricsBatchRequest true "Song IDs"
+// @Success 200 {object} LyricsBatch
+// @Router /songs/lyrics/batch [post]
+func getLyricsBatch(c *gin.Context) {
+	var req LyricsBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	refs, ok := normalizeSongRefs(req.IDs)
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid ids, expected song IDs or UUIDs with ID_TYPE=uuid")
+		return
+	}
+	ids := slices.Compact(slices.Sorted(slices.Values(refs)))
+	if len(ids) == 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "ids must not be empty")
+		return
+	}
+	if len(ids) > cfg.MaxLyricsBatch {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("At most %d songs can be fetched at once", cfg.MaxLyricsBatch))
+		return
+	}
+
+	var songs []Song
+	if err := whereSongRefs(dbFrom(c).Select("id", "uuid", "text"), ids).Find(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get lyrics")
+		return
+	}
+	batch := LyricsBatch{Lyrics: make(map[SongRef]BatchLyrics, len(songs)), Missing: []SongRef{}}
+	for _, song := range songs {
+		verses := splitVerses(song.Text)
+		if verses == nil {
+			verses = []string{}
+		}
+		batch.Lyrics[songRef(song)] = BatchLyrics{Text: song.Text, Verses: verses}
+	}
+	for _, id := range ids {
+		if _, ok := batch.Lyrics[id]; !ok {
+			batch.Missing = append(batch.Missing, id)
+		}
+	}
+	respondJSON(c, http.StatusOK, batch)
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSplitVerses(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: nil},
+		{name: "whitespace only", text: " \n\t\n\r\n  ", want: nil},
+		{name: "single verse", text: "one\ntwo", want: []string{"one\ntwo"}},
+		{name: "blank line separates verses", text: "one\n\ntwo", want: []string{"one", "two"}},
+		{name: "several blank lines", text: "one\n\n \n\t\ntwo", want: []string{"one", "two"}},
+		{name: "leading and trailing blank lines", text: "\n\none\n\n", want: []string{"one"}},
+		{name: "crlf line endings", text: "one\r\ntwo\r\n\r\nthree", want: []string{"one\ntwo", "three"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitVerses(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitVerses(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLyricStats(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want LyricStats
+	}{
+		{"empty", "", LyricStats{}},
+		{"blank lines don't count", "one two\n\n  \nthree", LyricStats{WordCount: 3, LineCount: 2, ReadingTimeSeconds: 1}},
+		{"extra whitespace", "  one\t two  ", LyricStats{WordCount: 2, LineCount: 1, ReadingTimeSeconds: 1}},
+		{"reading time at 200 words a minute", strings.Repeat("word ", 400), LyricStats{WordCount: 400, LineCount: 1, ReadingTimeSeconds: 120}},
+		{"reading time rounds up", strings.Repeat("word ", 201), LyricStats{WordCount: 201, LineCount: 1, ReadingTimeSeconds: 61}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lyricStats(tt.text); got != tt.want {
+				t.Errorf("lyricStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithStats(t *testing.T) {
+	got := withStats(Song{Text: "Is this the real life?\nIs this just fantasy?\n\nCaught in a landslide"})
+	if got.VerseCount != 2 || got.WordCount != 13 || got.LineCount != 3 || got.ReadingTimeSeconds != 4 {
+		t.Errorf("withStats() = verses %d, words %d, lines %d, reading time %ds, want 2, 13, 3, 4s",
+			got.VerseCount, got.WordCount, got.LineCount, got.ReadingTimeSeconds)
+	}
+}
+
+func TestLimitText(t *testing.T) {
+	previous := cfg
+	cfg.MaxTextLength = 20
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		name     string
+		text     string
+		truncate bool
+		want     string
+		wantOK   bool
+	}{
+		{"within the limit", "short", false, "short", true},
+		{"at the limit", strings.Repeat("a", 20), false, strings.Repeat("a", 20), true},
+		{"too long", strings.Repeat("a", 21), false, strings.Repeat("a", 21), false},
+		{"truncated", strings.Repeat("a", 30), true, "aaaaaaaa [truncated]", true},
+		{"counts characters, not bytes", strings.Repeat("ä", 20), false, strings.Repeat("ä", 20), true},
+		{"truncates on characters", strings.Repeat("ä", 21), true, "ääääääää [truncated]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := limitText(tt.text, tt.truncate)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("limitText() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckTextLength(t *testing.T) {
+	previous := cfg
+	cfg.MaxTextLength = 20
+	t.Cleanup(func() { cfg = previous })
+
+	song := Song{Text: strings.Repeat("a", 21)}
+	var invalid invalidSongError
+	if err := checkTextLength(&song, checkOptions{}); !errors.As(err, &invalid) {
+		t.Errorf("checkTextLength() error = %v, want an invalidSongError", err)
+	}
+	if err := checkTextLength(&song, checkOptions{truncate: true}); err != nil {
+		t.Fatalf("checkTextLength() with truncate error = %v", err)
+	}
+	if utf8.RuneCountInString(song.Text) != 20 || !strings.HasSuffix(song.Text, truncatedMarker) {
+		t.Errorf("text = %q, want it truncated to 20 characters", song.Text)
+	}
+}
+
+func TestRespondVerse(t *testing.T) {
+	verses := []string{"one", "two", "three"}
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		verse      string
+		wantStatus int
+		want       VerseContext
+	}{
+		{"1", http.StatusOK, VerseContext{Verse: 1, VerseCount: 3, Text: "one", Next: str("two")}},
+		{"2", http.StatusOK, VerseContext{Verse: 2, VerseCount: 3, Text: "two", Previous: str("one"), Next: str("three")}},
+		{"3", http.StatusOK, VerseContext{Verse: 3, VerseCount: 3, Text: "three", Previous: str("two")}},
+		{"0", http.StatusNotFound, VerseContext{}},
+		{"4", http.StatusNotFound, VerseContext{}},
+		{"first", http.StatusBadRequest, VerseContext{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.verse, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs/1/lyrics?verse="+tt.verse, nil)
+			respondVerse(c, verses, tt.verse)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got VerseContext
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("verse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageVerses(t *testing.T) {
+	previous := cfg
+	cfg.MaxVerses = 3
+	t.Cleanup(func() { cfg = previous })
+	verses := []string{"one", "two", "three", "four", "five"}
+
+	tests := []struct {
+		query         string
+		wantOK        bool
+		wantLyrics    []string
+		wantTruncated bool
+	}{
+		{"", true, []string{"one", "two", "three"}, true},
+		{"page=1&per_page=2", true, []string{"one", "two"}, false},
+		{"page=3&per_page=2", true, []string{"five"}, false},
+		{"page=4&per_page=2", true, []string{}, false},
+		{"per_page=3", true, []string{"one", "two", "three"}, false},
+		{"page=2", true, []string{"four", "five"}, false},
+		{"page=0", false, nil, false},
+		{"page=one", false, nil, false},
+		{"per_page=4", false, nil, false},
+		{"per_page=0", false, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs/1/lyrics?"+tt.query, nil)
+			result := gin.H{}
+			ok := pageVerses(c, verses, result)
+			if ok != tt.wantOK {
+				t.Fatalf("pageVerses() = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+				}
+				return
+			}
+			if got := result["lyrics"]; !reflect.DeepEqual(got, tt.wantLyrics) {
+				t.Errorf("lyrics = %q, want %q", got, tt.wantLyrics)
+			}
+			if truncated := result["truncated"] == true; truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestGetFullLyrics(t *testing.T) {
+	testDB(t)
+	tests := []struct {
+		text string
+		want FullLyrics
+	}{
+		{"one\nline\r\n\n\ntwo\n", FullLyrics{Raw: "one\nline\r\n\n\ntwo\n", Verses: []string{"one\nline", "two"}, VerseCount: 2, LineCount: 3}},
+		{"", FullLyrics{Raw: "", Verses: []string{}}},
+	}
+	r := gin.New()
+	r.GET("/songs/:id/lyrics/full", getFullLyrics)
+	for i, tt := range tests {
+		song := Song{Group: "Queen", Song: "Innuendo " + strconv.Itoa(i), Text: tt.text}
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+		w := serve(r, "/songs/"+strconv.Itoa(int(song.ID))+"/lyrics/full")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		var got FullLyrics
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("full lyrics of %q = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPutSongLyrics(t *testing.T) {
+	testDB(t)
+	if err := db.Create(&Song{Group: "Queen", Song: "Innuendo", Text: "old"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var song Song
+	if err := db.Take(&song, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.PUT("/songs/:id/lyrics", putSongLyrics)
+	put := func(ifMatch, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/songs/1/lyrics", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+	body := `{"text":"  one \n\n\n two  "}`
+
+	if w := put("", body); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("status without If-Match = %d, want %d", w.Code, http.StatusPreconditionRequired)
+	}
+	if w := put(`"stale"`, body); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("status with a stale If-Match = %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+	if w := put(songETag(song), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("status without text = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w := put(songETag(song), body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var stored Song
+	if err := db.Take(&stored, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Text != "one\n\ntwo" || stored.VerseCount != 2 {
+		t.Errorf("text, verse_count = %q, %d, want the normalized text with 2 verses", stored.Text, stored.VerseCount)
+	}
+	if got := w.Header().Get("ETag"); got != songETag(stored) {
+		t.Errorf("ETag = %s, want %s", got, songETag(stored))
+	}
+	var revisions int64
+	db.Model(&SongRevision{}).Where("song_id = ?", 1).Count(&revisions)
+	if revisions != 1 {
+		t.Errorf("revisions = %d, want the previous lyrics saved", revisions)
+	}
+
+	if w := put(songETag(song), body); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("status reusing the old ETag = %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+}
+
+func TestLyricsPreview(t *testing.T) {
+	long := strings.Repeat("la ", previewLength)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"first verse only", "one\ntwo\n\nthree", "one\ntwo"},
+		{"leading blank lines", "\n\n  \none", "one"},
+		{"long verse", long, strings.TrimRight(long[:previewLength], " ") + "…"},
+		{"multi-byte runes", strings.Repeat("ж", previewLength+1), strings.Repeat("ж", previewLength) + "…"},
+		{"exactly the limit", strings.Repeat("ж", previewLength), strings.Repeat("ж", previewLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lyricsPreview(tt.text)
+			if got != tt.want {
+				t.Errorf("lyricsPreview() = %q, want %q", got, tt.want)
+			}
+			if n := utf8.RuneCountInString(strings.TrimSuffix(got, "…")); n > previewLength {
+				t.Errorf("preview has %d characters, want at most %d", n, previewLength)
+			}
+		})
+	}
+}
+
+func TestGetSongsPreview(t *testing.T) {
+	testDB(t)
+	if err := db.Create(&Song{Group: "Queen", Song: "Innuendo", Text: "While the sun hangs in the sky\n\nAnd the desert has sand"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/songs", getSongs)
+	w := serve(r, "/songs?preview=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var songs []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &songs); err != nil {
+		t.Fatal(err)
+	}
+	if len(songs) != 1 || songs[0]["preview"] != "While the sun hangs in the sky" {
+		t.Fatalf("songs = %v, want one song with the first verse as preview", songs)
+	}
+	if _, ok := songs[0]["text"]; ok {
+		t.Errorf("song = %v, want the text left out", songs[0])
+	}
+}
+
+func postLyricsBatch(r http.Handler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/songs/lyrics/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetLyricsBatchRejectsInvalidIDs(t *testing.T) {
+	withIDType(t, idTypeInt)
+	previous := cfg.MaxLyricsBatch
+	cfg.MaxLyricsBatch = 2
+	t.Cleanup(func() { cfg.MaxLyricsBatch = previous })
+	r := gin.New()
+	r.POST("/songs/lyrics/batch", getLyricsBatch)
+
+	for _, body := range []string{`{}`, `{"ids":[]}`, `{"ids":["x"]}`, `{"ids":[1,2,3]}`} {
+		if w := postLyricsBatch(r, body); w.Code != http.StatusBadRequest {
+			t.Errorf("status for %s = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetLyricsBatch(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo", Text: "one\n\ntwo"},
+		{Group: "Queen", Song: "Demo"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.POST("/songs/lyrics/batch", getLyricsBatch)
+
+	w := postLyricsBatch(r, `{"ids":[2,1,9,1]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var got struct {
+		Lyrics  map[string]BatchLyrics `json:"lyrics"`
+		Missing []int                  `json:"missing"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]BatchLyrics{
+		"1": {Text: "one\n\ntwo", Verses: []string{"one", "two"}},
+		"2": {Text: "", Verses: []string{}},
+	}
+	if !reflect.DeepEqual(got.Lyrics, want) {
+		t.Errorf("lyrics = %+v, want %+v", got.Lyrics, want)
+	}
+	if !reflect.DeepEqual(got.Missing, []int{9}) {
+		t.Errorf("missing = %v, want [9]", got.Missing)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LyricMatch struct {
+	Verse  int `json:"verse"`
+	Offset int `json:"offset"`
+}
+
+type LyricSearchResult struct {
+	SongID  SongRef      `json:"song_id" swaggertype:"string"`
+	Group   string       `json:"group"`
+	Song    string       `json:"song"`
+	Matches []LyricMatch `json:"matches"`
+}
+
+// findMatches returns every case-insensitive occurrence of phrase in the
+// verses of text, with the 1-based verse and the offset in characters into
+// the verse. Matches may overlap.
+func findMatches(text, phrase string) []LyricMatch {
+	needle := []rune(strings.ToLower(phrase))
+	var matches []LyricMatch
+	for i, verse := range splitVerses(text) {
+		haystack := []rune(verse)
+		for offset := 0; offset+len(needle) <= len(haystack); offset++ {
+			if runesEqualFold(haystack[offset:offset+len(needle)], needle) {
+				matches = append(matches, LyricMatch{Verse: i + 1, Offset: offset})
+			}
+		}
+	}
+	return matches
+}
+
+// runesEqualFold compares a to the lowercased b rune by rune, so offsets
+// stay in characters of the original text.
+func runesEqualFold(a, b []rune) bool {
+	for i := range a {
+		if unicode.ToLower(a[i]) != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// @Summary Search lyrics
+// @Description Get every occurrence of a phrase in the lyrics of the catalog, per song with the verse and character offset of each match
+// @Produce json
+// @Param q query string true "Phrase"
+// @Param limit query int false "Songs per page"
+// @Param offset query int false "Offset"
+// @Success 200 {array} LyricSearchResult
+// @Router /lyrics/search [get]
+func searchLyrics(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Missing q parameter")
+		return
+	}
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	var songs []Song
+	err := dbFrom(c).Select("id", "uuid", "group", "song", "text").
+		Where("text ILIKE ?", "%"+likeEscaper.Replace(q)+"%").
+		Order("id").Limit(limit).Offset(offset).Find(&songs).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to search lyrics")
+		return
+	}
+
+	results := []LyricSearchResult{}
+	for _, song := range songs {
+		// A phrase spanning a verse break matches in SQL but not in any verse.
+		if matches := findMatches(song.Text, q); len(matches) > 0 {
+			results = append(results, LyricSearchResult{SongID: songRef(song), Group: song.Group, Song: song.Song, Matches: matches})
+		}
+	}
+	respondJSON(c, http.StatusOK, results)
+}
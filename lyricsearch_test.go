@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		phrase string
+		want   []LyricMatch
+	}{
+		{
+			name:   "twice in one verse",
+			text:   "Let it be, let it be",
+			phrase: "let it be",
+			want:   []LyricMatch{{Verse: 1, Offset: 0}, {Verse: 1, Offset: 11}},
+		},
+		{
+			name:   "in two verses",
+			text:   "Hey Jude, don't make it bad\n\nHey Jude, don't be afraid",
+			phrase: "jude",
+			want:   []LyricMatch{{Verse: 1, Offset: 4}, {Verse: 2, Offset: 4}},
+		},
+		{
+			name:   "offsets count characters, not bytes",
+			text:   "Café café",
+			phrase: "CAFÉ",
+			want:   []LyricMatch{{Verse: 1, Offset: 0}, {Verse: 1, Offset: 5}},
+		},
+		{
+			name:   "overlapping",
+			text:   "nanana",
+			phrase: "nana",
+			want:   []LyricMatch{{Verse: 1, Offset: 0}, {Verse: 1, Offset: 2}},
+		},
+		{
+			name:   "no match",
+			text:   "Yesterday",
+			phrase: "tomorrow",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findMatches(tt.text, tt.phrase); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findMatches() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxLyricSimilar is how many similar songs are computed and cached per
+	// song; the limit parameter picks from those.
+	maxLyricSimilar = 20
+	// maxRareWords bounds how many of a song's rarest words are matched
+	// against the library.
+	maxRareWords = 25
+	// lyricSimilarTTL is how long computed matches are reused.
+	lyricSimilarTTL = 10 * time.Minute
+)
+
+type SimilarLyrics struct {
+	ID          SongRef  `json:"id" swaggertype:"string"`
+	Group       string   `json:"group"`
+	Song        string   `json:"song"`
+	Score       float64  `json:"score"`
+	SharedWords []string `json:"shared_words"`
+}
+
+// lyricSimilarSQL scores songs by the rare words they share with a song.
+// The song's lyric words are weighted by inverse document frequency,
+// ln(songs with lyric words / songs using the word), and only the
+// maxRareWords rarest of them that some other song uses are matched. A
+// song's score is the sum of the weights of the words it shares. Only songs
+// sharing a word are read, through idx_songs_lyric_words.
+const lyricSimilarSQL = `WITH usage AS (
+		SELECT songs.id, word FROM songs, unnest(string_to_array(lyric_words, ' ')) AS word
+		WHERE string_to_array(lyric_words, ' ') && string_to_array(@words, ' ')
+			AND word = ANY (string_to_array(@words, ' '))
+	), rare AS (
+		SELECT word, ln((SELECT count(*) FROM songs WHERE lyric_words <> '')::float / count(*)) AS weight
+		FROM usage GROUP BY word HAVING count(*) > 1
+		ORDER BY count(*), word LIMIT @rare
+	)
+	SELECT songs.id, songs.uuid, songs."group", songs.song, sum(rare.weight) AS score,
+		string_agg(rare.word, ',' ORDER BY rare.weight DESC, rare.word) AS shared_words
+	FROM usage JOIN rare USING (word) JOIN songs ON songs.id = usage.id
+	WHERE usage.id <> @id
+	GROUP BY songs.id, songs.uuid, songs."group", songs.song
+	ORDER BY score DESC, songs.id LIMIT @limit`
+
+// lyricWordsBatchSize is how many songs backfillLyricWords loads at a time.
+const lyricWordsBatchSize = 500
+
+// lyricWords returns the words of text lyric similarity matches on, sorted,
+// distinct and separated by spaces: lowercase runs of letters, digits and
+// apostrophes longer than two characters, without stopwords.
+func lyricWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	words = slices.DeleteFunc(words, func(word string) bool {
+		return utf8.RuneCountInString(word) <= 2 || slices.Contains(stopwords, word)
+	})
+	slices.Sort(words)
+	return strings.Join(slices.Compact(words), " ")
+}
+
+// backfillLyricWords derives the lyric words of songs stored before they
+// existed.
+func backfillLyricWords() {
+	var songs []Song
+	backfilled := 0
+	result := db.Select("id", "text").Where("lyric_words IS NULL").
+		FindInBatches(&songs, lyricWordsBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, song := range songs {
+				if err := db.Model(&Song{}).Where("id = ?", song.ID).
+					UpdateColumn("lyric_words", lyricWords(song.Text)).Error; err != nil {
+					return err
+				}
+			}
+			backfilled += len(songs)
+			return nil
+		})
+	if result.Error != nil {
+		logrus.Errorf("Failed to backfill lyric words: %v", result.Error)
+	} else if backfilled > 0 {
+		logrus.Infof("Backfilled lyric words for %d songs", backfilled)
+	}
+}
+
+// lyricSimilarCache holds computed matches per song. A change to a song
+// drops its own matches and those it appears in. The word weights of the
+// other entries may drift a little with the change, and a new song only
+// shows up in them once they expire, which lyricSimilarTTL bounds.
+type lyricSimilarCache struct {
+	mu      sync.Mutex
+	entries map[uint]lyricSimilarEntry
+}
+
+type lyricSimilarEntry struct {
+	matches   []SimilarLyrics
+	expiresAt time.Time
+}
+
+var lyricSimilar = lyricSimilarCache{entries: make(map[uint]lyricSimilarEntry)}
+
+func init() {
+	subscribeSongChanges(func(song Song, _ string) { lyricSimilar.invalidate(song) })
+}
+
+func (lc *lyricSimilarCache) get(id uint) ([]SimilarLyrics, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	entry, ok := lc.entries[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.matches, true
+}
+
+func (lc *lyricSimilarCache) set(id uint, matches []SimilarLyrics) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.entries[id] = lyricSimilarEntry{matches: matches, expiresAt: time.Now().Add(lyricSimilarTTL)}
+}
+
+func (lc *lyricSimilarCache) invalidate(song Song) {
+	ref := songRef(song)
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	delete(lc.entries, song.ID)
+	for id, entry := range lc.entries {
+		if slices.ContainsFunc(entry.matches, func(match SimilarLyrics) bool { return match.ID == ref }) {
+			delete(lc.entries, id)
+		}
+	}
+}
+
+// @Summary Get songs with similar lyrics
+// @Description Get songs sharing distinctive words with the lyrics of a song, ranked by the summed rarity of the shared words
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param limit query int false "Limit (default 10, max 20)"
+// @Success 200 {array} SimilarLyrics
+// @Failure 404 {object} APIError
+// @Router /songs/{id}/lyric-similar [get]
+func getLyricSimilarSongs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > maxLyricSimilar {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("limit must be between 1 and %d", maxLyricSimilar))
+		return
+	}
+	var song Song
+	if !findSong(c, dbFrom(c).Select("id", "text"), &song) {
+		return
+	}
+
+	matches, ok := lyricSimilar.get(song.ID)
+	if !ok {
+		var rows []struct {
+			ID          uint
+			UUID        string
+			Group       string
+			Song        string
+			Score       float64
+			SharedWords string
+		}
+		err := dbFrom(c).Raw(lyricSimilarSQL, map[string]any{
+			"words": lyricWords(song.Text),
+			"rare":  maxRareWords,
+			"id":    song.ID,
+			"limit": maxLyricSimilar,
+		}).Scan(&rows).Error
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to find similar songs")
+			return
+		}
+		matches = make([]SimilarLyrics, len(rows))
+		for i, row := range rows {
+			matches[i] = SimilarLyrics{
+				ID:          songRef(Song{ID: row.ID, UUID: row.UUID}),
+				Group:       row.Group,
+				Song:        row.Song,
+				Score:       row.Score,
+				SharedWords: strings.Split(row.SharedWords, ","),
+			}
+		}
+		lyricSimilar.set(song.ID, matches)
+	}
+	respondJSON(c, http.StatusOK, matches[:min(limit, len(matches))])
+}
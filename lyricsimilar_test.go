@@ -0,0 +1,56 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLyricSimilarCacheInvalidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed Song
+		want    []uint
+	}{
+		{"song itself", Song{ID: 1}, []uint{2, 3}},
+		{"appears in matches", Song{ID: 4}, []uint{1, 3}},
+		{"unrelated", Song{ID: 5}, []uint{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withIDType(t, idTypeInt)
+			cache := lyricSimilarCache{entries: make(map[uint]lyricSimilarEntry)}
+			cache.set(1, []SimilarLyrics{{ID: songRef(Song{ID: 2})}})
+			cache.set(2, []SimilarLyrics{{ID: songRef(Song{ID: 4})}})
+			cache.set(3, []SimilarLyrics{{ID: songRef(Song{ID: 6})}})
+
+			cache.invalidate(tt.changed)
+			var got []uint
+			for id := range cache.entries {
+				got = append(got, id)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("cached songs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLyricWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"Oh, the night is young and the night is ours", "night ours young"},
+		{"Rock'n'roll! ROCK'N'ROLL, baby\n\nBaby don't stop", "baby rock'n'roll stop"},
+		{"Ça ira, ça ira", "ira"},
+		{"Tränen lügen nicht", "lügen nicht tränen"},
+		{"1999 was 2 years ago", "1999 ago years"},
+	}
+	for _, tt := range tests {
+		if got := lyricWords(tt.text); got != tt.want {
+			t.Errorf("lyricWords(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
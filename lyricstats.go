@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// lyricStatsBatchSize is how many songs recomputeLyricStats loads at a time.
+const lyricStatsBatchSize = 500
+
+// lyricStatsColumns are the columns setLyricStats derives from the text.
+// Updates that select text must select them too.
+var lyricStatsColumns = []string{"verse_count", "word_count", "line_count", "lyric_words"}
+
+// setLyricStats stores the verse, word and line counts and the lyric words
+// of song's text on it.
+func setLyricStats(song *Song) {
+	stats := lyricStats(song.Text)
+	song.VerseCount = len(splitVerses(song.Text))
+	song.WordCount = stats.WordCount
+	song.LineCount = stats.LineCount
+	song.LyricWords = lyricWords(song.Text)
+}
+
+var recomputingLyricStats atomic.Bool
+
+// recomputeLyricStats corrects the stored lyric stats of every song. Like
+// the link check it isn't an edit, so updated_at is left alone.
+func recomputeLyricStats() {
+	defer recomputingLyricStats.Store(false)
+
+	var songs []Song
+	corrected := 0
+	result := db.Select(append([]string{"id", "text"}, lyricStatsColumns...)).
+		FindInBatches(&songs, lyricStatsBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, song := range songs {
+				stored := song
+				setLyricStats(&song)
+				if song.VerseCount == stored.VerseCount && song.WordCount == stored.WordCount &&
+					song.LineCount == stored.LineCount && song.LyricWords == stored.LyricWords {
+					continue
+				}
+				if err := db.Model(&Song{}).Where("id = ?", song.ID).UpdateColumns(map[string]any{
+					"verse_count": song.VerseCount,
+					"word_count":  song.WordCount,
+					"line_count":  song.LineCount,
+					"lyric_words": song.LyricWords,
+				}).Error; err != nil {
+					return err
+				}
+				corrected++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		logrus.Errorf("Failed to recompute lyric stats: %v", result.Error)
+		return
+	}
+	logrus.Infof("Recomputed lyric stats of %d songs, %d corrected", result.RowsAffected, corrected)
+}
+
+// @Summary Recompute lyric stats
+// @Description Start recomputing the stored verse_count, word_count and line_count of every song in the background. They are kept up to date on writes; this corrects songs stored before they existed or changed outside the API.
+// @Produce json
+// @Success 202 {object} map[string]string
+// @Failure 409 {object} APIError
+// @Router /admin/recompute-lyric-stats [post]
+func startLyricStatsRecompute(c *gin.Context) {
+	if !recomputingLyricStats.CompareAndSwap(false, true) {
+		respondError(c, http.StatusConflict, codeConflict, "Lyric stats are already being recomputed")
+		return
+	}
+	// Other replicas may be recomputing them as well.
+	lock, ok, err := tryJobLock("recompute-lyric-stats")
+	if err != nil || !ok {
+		recomputingLyricStats.Store(false)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to start recomputing lyric stats")
+		} else {
+			respondError(c, http.StatusConflict, codeConflict, "Lyric stats are already being recomputed")
+		}
+		return
+	}
+	go func() {
+		defer lock.release()
+		recomputeLyricStats()
+	}()
+	respondJSON(c, http.StatusAccepted, gin.H{"message": "Recomputing lyric stats"})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetLyricStats(t *testing.T) {
+	song := Song{Text: "While the sun\nhangs in the sky\n\n\nSun, sun"}
+	setLyricStats(&song)
+	if song.VerseCount != 2 || song.WordCount != 9 || song.LineCount != 3 {
+		t.Errorf("verses, words, lines = %d, %d, %d, want 2, 9, 3", song.VerseCount, song.WordCount, song.LineCount)
+	}
+	if song.LyricWords != "hangs sky sun while" {
+		t.Errorf("lyric words = %q, want %q", song.LyricWords, "hangs sky sun while")
+	}
+
+	song.Text = ""
+	setLyricStats(&song)
+	if song.VerseCount != 0 || song.WordCount != 0 || song.LineCount != 0 || song.LyricWords != "" {
+		t.Errorf("stats of empty text = %+v, want zero", song)
+	}
+}
+
+func TestStartLyricStatsRecomputeAlreadyRunning(t *testing.T) {
+	recomputingLyricStats.Store(true)
+	t.Cleanup(func() { recomputingLyricStats.Store(false) })
+	r := gin.New()
+	r.POST("/admin/recompute-lyric-stats", startLyricStatsRecompute)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/recompute-lyric-stats", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func TestRecomputeLyricStats(t *testing.T) {
+	testDB(t)
+	song := Song{Group: "Queen", Song: "Innuendo", Text: "While the sun hangs in the sky\n\nAnd the desert has sand"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Stats as left by a change outside the API.
+	if err := db.Model(&Song{}).Where("id = ?", song.ID).UpdateColumns(map[string]any{
+		"verse_count": 0, "word_count": 0, "line_count": 0, "lyric_words": "",
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var before Song
+	if err := db.Take(&before, song.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recomputingLyricStats.Store(true)
+	recomputeLyricStats()
+	if recomputingLyricStats.Load() {
+		t.Error("recomputeLyricStats() left the job marked as running")
+	}
+
+	var got Song
+	if err := db.Take(&got, song.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.VerseCount != 2 || got.WordCount != 11 || got.LineCount != 2 || got.LyricWords != lyricWords(song.Text) {
+		t.Errorf("stats = %d, %d, %d, %q, want them recomputed", got.VerseCount, got.WordCount, got.LineCount, got.LyricWords)
+	}
+	if !got.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("updated_at = %v, want it left at %v", got.UpdatedAt, before.UpdatedAt)
+	}
+}
@@ -1,22 +1,32 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	_ "github.com/swaggo/swag/example/basic/docs"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 )
 
+//go:generate swag init -g main.go
+
 // @title Music Library API
 // @version 1.0
 // @description API for managing an online music library.
@@ -24,24 +34,132 @@ import (
 // @BasePath /
 
 type Song struct {
-	ID          uint   `json:"id" gorm:"primaryKey"`
-	Group       string `json:"group"`
-	Song        string `json:"song"`
-	ReleaseDate string `json:"release_date"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Group         string         `json:"group" binding:"required" gorm:"size:256"`
+	Song          string         `json:"song" binding:"required" gorm:"size:256"`
+	GroupKey      string         `json:"-" gorm:"size:256"`
+	SongKey       string         `json:"-" gorm:"size:256"`
+	ReleaseDate   string         `json:"release_date"`
+	Text          string         `json:"text"`
+	Link          string         `json:"link"`
+	CoverURL      string         `json:"cover_url"`
+	Links         []SongLink     `json:"links" gorm:"constraint:OnDelete:CASCADE"`
+	Revisions     []SongRevision `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Lyrics        []SongLyrics   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	PlayDays      []SongPlayDay  `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Flagged       bool           `json:"flagged"`
+	LinkStatus    string         `json:"link_status,omitempty"`
+	LinkCheckedAt *time.Time     `json:"link_checked_at,omitempty"`
+	UUID          string         `json:"uuid" gorm:"type:uuid;default:gen_random_uuid();uniqueIndex"`
+	PlayCount     int64          `json:"play_count" gorm:"not null;default:0"`
+	VerseCount    int            `json:"verse_count" gorm:"not null;default:0"`
+	WordCount     int            `json:"word_count" gorm:"not null;default:0"`
+	LineCount     int            `json:"line_count" gorm:"not null;default:0"`
+	// LyricWords are the words of the text lyric similarity matches on, see
+	// lyricWords.
+	LyricWords string `json:"-"`
+	// EnrichmentPending is set on a created song whose enrichment timed out
+	// and is being finished in the background.
+	EnrichmentPending bool      `json:"enrichment_pending,omitempty" gorm:"-"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// BeforeSave derives the stored search keys and lyric stats of the song.
+func (s *Song) BeforeSave(*gorm.DB) error {
+	s.GroupKey, s.SongKey = searchKey(s.Group), searchKey(s.Song)
+	setLyricStats(s)
+	return nil
 }
 
 var db *gorm.DB
 
+var gormLogLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// gormLogger routes GORM's logging through logrus. At the info level every
+// query is logged with its bindings and timing.
+func gormLogger(level string) logger.Interface {
+	logLevel, ok := gormLogLevels[level]
+	if !ok {
+		logLevel = logger.Warn
+	}
+	return logger.New(logrus.StandardLogger(), logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  logLevel,
+		IgnoreRecordNotFoundError: true,
+	})
+}
+
+// dbFrom returns db bound to the request context, so queries are cancelled
+// when the request times out or the client goes away.
+func dbFrom(c *gin.Context) *gorm.DB {
+	return db.WithContext(c.Request.Context())
+}
+
+// maxNameColumnSize is the size of the group and song columns, matching the
+// size tags on Song.
+const maxNameColumnSize = 256
+
+// truncateLongNames cuts groups and titles that predate the column size
+// down to it, as the migration to the sized columns fails otherwise.
+func truncateLongNames() {
+	if !db.Migrator().HasTable(&Song{}) {
+		return
+	}
+	result := db.Exec(`UPDATE songs SET "group" = left("group", ?), song = left(song, ?)
+		WHERE length("group") > ? OR length(song) > ?`,
+		maxNameColumnSize, maxNameColumnSize, maxNameColumnSize, maxNameColumnSize)
+	if result.Error != nil {
+		logrus.Errorf("Failed to truncate long names: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		logrus.Warnf("Truncated the group or title of %d songs to %d characters", result.RowsAffected, maxNameColumnSize)
+	}
+}
+
 func initDB() {
-	dsn := os.Getenv("DATABASE_URL")
+	if strings.TrimSpace(cfg.DatabaseURL) == "" {
+		logrus.Fatal(errDatabaseURLMissing)
+	}
 	var err error
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err = gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{
+		Logger: gormLogger(cfg.GormLogLevel),
+		// Match PostgreSQL's timestamp precision so in-memory timestamps,
+		// and the ETags derived from them, equal what is stored.
+		NowFunc: func() time.Time { return time.Now().Truncate(time.Microsecond) },
+	})
 	if err != nil {
-		log.Fatal("Failed to connect to database")
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	truncateLongNames()
+	db.AutoMigrate(&Song{}, &SongLink{}, &SongRevision{}, &SongLyrics{}, &SongPlayDay{}, &ImportJob{})
+	// Lookups by name go through the search keys, which replace the indexes
+	// on lower() of the names.
+	db.Exec(`DROP INDEX IF EXISTS idx_songs_song_prefix`)
+	db.Exec(`DROP INDEX IF EXISTS idx_songs_group_prefix`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_songs_song_key ON songs (song_key text_pattern_ops)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_songs_group_key ON songs (group_key text_pattern_ops)`)
+	// Fuzzy lookups pre-filter candidates by trigram similarity of the keys.
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error; err != nil {
+		logrus.Errorf("Failed to create the pg_trgm extension, fuzzy song lookups need it: %v", err)
 	}
-	db.AutoMigrate(&Song{})
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_songs_song_key_trgm ON songs USING gin (song_key gin_trgm_ops)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_songs_group_key_trgm ON songs USING gin (group_key gin_trgm_ops)`)
+	// Duplicates that predate the index keep it from being created until
+	// they are merged or renamed.
+	if err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_group_song ON songs ("group", song)`).Error; err != nil {
+		logrus.Errorf("Failed to create the unique index on group and song, rename or delete the duplicate songs: %v", err)
+	}
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_songs_lyric_words ON songs USING gin (string_to_array(lyric_words, ' '))`)
+	backfillSearchKeys()
+	backfillLyricWords()
+	backfillSongLinks()
+	backfillSongLyrics()
+	prunePlayDays()
 }
 
 // @Summary Get all songs with filtering and pagination
@@ -49,21 +167,67 @@ func initDB() {
 // @Produce json
 // @Param group query string false "Group Name"
 // @Param song query string false "Song Name"
+// @Param exclude_group query string false "Comma-separated groups to leave out (at most 50)"
+// @Param preview query bool false "Replace the lyrics of each song with a preview of its first verse"
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
+// @Param sort query string false "Comma-separated sort fields, prefixed with - for descending"
+// @Param locale query string false "Locale whose collation is used to sort group and song"
+// @Param safe query bool false "Exclude songs flagged by the profanity filter"
+// @Param has_link query bool false "Only songs with (true) or without (false) a valid link"
+// @Param has_cover query bool false "Only songs with (true) or without (false) a cover"
+// @Param has_text query bool false "Only songs with (true) or without (false) lyrics"
+// @Param link_status query string false "Only songs whose link was last checked as ok, broken or unknown"
+// @Param updated_since query string false "Only songs updated since this RFC 3339 time, or since the start of this YYYY-MM-DD date in DEFAULT_TIMEZONE"
+// @Param Accept-Language header string false "Locale used to format release dates"
+// @Param If-Modified-Since header string false "Only return the listing if songs changed since this time"
 // @Success 200 {array} Song
+// @Header 200 {string} Deprecation "Set when the deprecated offset parameter is used"
+// @Success 304
 // @Router /songs [get]
 func getSongs(c *gin.Context) {
+	if !checkSongFilters(c) {
+		return
+	}
 	var songs []Song
-	query := db
+	query := filterSongs(c, dbFrom(c))
 
-	if group := c.Query("group"); group != "" {
-		query = query.Where("group = ?", group)
+	if notModified(c, filterSongs(c, dbFrom(c).Model(&Song{}))) {
+		c.Status(http.StatusNotModified)
+		return
 	}
-	if song := c.Query("song"); song != "" {
-		query = query.Where("song = ?", song)
+
+	query, ok := sortSongs(c, query)
+	if !ok {
+		return
+	}
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+	preview := c.Query("preview") == "true"
+	if preview {
+		query = query.Omit("text")
 	}
+	query.Preload("Links").Limit(limit).Offset(offset).Find(&songs)
 
+	localizeSongs(c, songs)
+	if preview {
+		previews, err := songPreviews(dbFrom(c), songs)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to load previews")
+			return
+		}
+		respondJSON(c, http.StatusOK, previews)
+		return
+	}
+	respondJSON(c, http.StatusOK, songs)
+}
+
+// pagination reads the limit and offset query parameters, capping the limit
+// at MAX_LIMIT. It writes the error response and returns false when the
+// limit is below 1 or the offset is negative or beyond MAX_OFFSET.
+func pagination(c *gin.Context) (int, int, bool) {
 	limitStr := c.DefaultQuery("limit", "10")
 	offsetStr := c.DefaultQuery("offset", "0")
 
@@ -76,109 +240,575 @@ func getSongs(c *gin.Context) {
 	if err != nil {
 		offset = 0
 	}
+	if limit < 1 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "limit must be at least 1")
+		return 0, 0, false
+	}
+	if offset < 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "offset must not be negative")
+		return 0, 0, false
+	}
+	if offset > cfg.MaxOffset {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf(
+			"Offset exceeds the maximum of %d; narrow the results with filters instead of paging this deep", cfg.MaxOffset))
+		return 0, 0, false
+	}
+	return min(limit, cfg.MaxLimit), offset, true
+}
+
+// validLinkPattern matches links that look like absolute http(s) URLs.
+const validLinkPattern = `^https?://[^[:space:]/]+`
+
+// maxExcludedGroups bounds how many groups exclude_group may list.
+const maxExcludedGroups = 50
+
+// excludedGroups returns the comma-separated groups in exclude_group.
+func excludedGroups(c *gin.Context) []string {
+	var groups []string
+	for _, group := range strings.Split(c.Query("exclude_group"), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
 
-	query.Limit(limit).Offset(offset).Find(&songs)
+// checkSongFilters validates the listing filters that filterSongs can't
+// silently ignore. It writes the error response and returns false when one
+// is invalid.
+func checkSongFilters(c *gin.Context) bool {
+	if len(excludedGroups(c)) > maxExcludedGroups {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("exclude_group lists more than %d groups", maxExcludedGroups))
+		return false
+	}
+	return true
+}
 
-	c.JSON(http.StatusOK, songs)
+// filterSongs applies the listing filters from the query string to query.
+func filterSongs(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if group := c.Query("group"); group != "" {
+		query = query.Where(`"group" = ?`, group)
+	}
+	if song := c.Query("song"); song != "" {
+		query = query.Where("song = ?", song)
+	}
+	if groups := excludedGroups(c); len(groups) > 0 {
+		query = query.Where(`"group" NOT IN ?`, groups)
+	}
+	if c.Query("safe") == "true" {
+		query = query.Where("flagged = ?", false)
+	}
+	switch c.Query("has_link") {
+	case "true":
+		query = query.Where("link ~* ?", validLinkPattern)
+	case "false":
+		query = query.Where("link IS NULL OR link !~* ?", validLinkPattern)
+	}
+	if since, ok := parseUpdatedSince(c.Query("updated_since")); ok {
+		query = query.Where("updated_at >= ?", since)
+	}
+	if status := c.Query("link_status"); linkStatuses[status] {
+		query = query.Where("link_status = ?", status)
+	}
+	switch c.Query("has_cover") {
+	case "true":
+		query = query.Where("cover_url <> ''")
+	case "false":
+		query = query.Where("cover_url IS NULL OR cover_url = ''")
+	}
+	switch c.Query("has_text") {
+	case "true":
+		query = query.Where(hasTextSQL)
+	case "false":
+		query = query.Where("text IS NULL OR NOT (" + hasTextSQL + ")")
+	}
+	return query
+}
+
+// notModified sets Last-Modified from the latest update among the songs
+// matched by query and reports whether the client's If-Modified-Since copy
+// is still current.
+func notModified(c *gin.Context, query *gorm.DB) bool {
+	var lastModified sql.NullTime
+	if err := query.Select("max(updated_at)").Scan(&lastModified).Error; err != nil || !lastModified.Valid {
+		return false
+	}
+	modified := lastModified.Time.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", modified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	return err == nil && !modified.After(since)
 }
 
 // @Summary Get song lyrics with pagination
-// @Description Get lyrics of a song with pagination (verses per page)
+// @Description Get lyrics of a song with pagination (verses per page). Without pagination at most MAX_VERSES verses are returned and truncated is set if there are more.
 // @Produce json
-// @Param id path int true "Song ID"
-// @Param page query int true "Page number"
-// @Param per_page query int true "Verses per page"
-// @Success 200 {object} map[string]string
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param page query int false "Page number"
+// @Param per_page query int false "Verses per page"
+// @Param verse query int false "Return only this verse (1-based) with its neighbors"
+// @Param lang query string false "Language of the lyrics, defaults to the primary lyrics"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} APIError
 // @Router /songs/{id}/lyrics [get]
 func getSongLyrics(c *gin.Context) {
-	id := c.Param("id")
 	var song Song
-	if err := db.First(&song, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+
+	text, ok := lyricsText(c, song, c.Query("lang"))
+	if !ok {
+		return
+	}
+	verses := splitVerses(text)
+	if verse := c.Query("verse"); verse != "" {
+		respondVerse(c, verses, verse)
 		return
 	}
 
-	verses := strings.Split(song.Text, "\n")
-	c.JSON(http.StatusOK, gin.H{"lyrics": verses})
+	stats := lyricStats(text)
+	result := gin.H{
+		"verse_count":          len(verses),
+		"word_count":           stats.WordCount,
+		"line_count":           stats.LineCount,
+		"reading_time_seconds": stats.ReadingTimeSeconds,
+	}
+	if !pageVerses(c, verses, result) {
+		return
+	}
+	respondJSON(c, http.StatusOK, result)
+}
+
+// SongWithStats is a song with its lyric stats, including the reading time
+// that isn't stored.
+type SongWithStats struct {
+	Song
+	ReadingTimeSeconds int `json:"reading_time_seconds"`
+}
+
+// withStats fills in the lyric stats of song from its current text.
+func withStats(song Song) SongWithStats {
+	setLyricStats(&song)
+	return SongWithStats{Song: song, ReadingTimeSeconds: lyricStats(song.Text).ReadingTimeSeconds}
+}
+
+// @Summary Get a song
+// @Description Get a song by ID along with lyric statistics
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param Accept-Language header string false "Locale used to format release dates"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param include query string false "Related data to embed: links, revisions, translations"
+// @Success 200 {object} SongWithIncludes
+// @Success 304
+// @Router /songs/{id} [get]
+func getSong(c *gin.Context) {
+	query, ok := includeRelations(c, dbFrom(c).Preload("Links"))
+	if !ok {
+		return
+	}
+	var song Song
+	if !findSong(c, query, &song) {
+		return
+	}
+
+	// Translations can change without the song changing, so the ETag only
+	// stands for the song itself.
+	etag := songETag(song)
+	c.Header("ETag", etag)
+	if c.Query("include") == "" && etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	localizeSong(c, &song)
+	respondJSON(c, http.StatusOK, SongWithIncludes{
+		SongWithStats: withStats(song),
+		Revisions:     song.Revisions,
+		Translations:  song.Lyrics,
+	})
 }
 
 // @Summary Add a new song
-// @Description Add a new song to the library
+// @Description Add a new song to the library. If the enrichment providers don't respond within ENRICHMENT_TIMEOUT_MS, the song is created as sent with enrichment_pending set and enriched in the background.
 // @Accept json
 // @Produce json
+// @Param Accept-Language header string false "Locale used to format release dates, in which they are also accepted"
 // @Param song body Song true "Song Data"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
 // @Success 201 {object} Song
+// @Failure 409 {object} APIError "Another song has the same group and title"
+// @Failure 415 {object} APIError
 // @Router /songs [post]
 func addSong(c *gin.Context) {
 	var song Song
-	if err := c.ShouldBindJSON(&song); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	if err := bindSong(c, &song); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	var invalid invalidSongError
+	if err := createSong(c.Request.Context(), dbFrom(c), &song, requestCheckOptions(c)); errors.As(err, &invalid) {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, invalid.Error())
 		return
+	} else if conflict, ok := asConflict(err); ok {
+		respondConflict(c, conflict)
+		return
+	} else if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to create song")
+		return
+	}
+	respondCreatedSong(c, song)
+}
+
+// respondCreatedSong writes the response for a song the request created,
+// finishing enrichment that timed out in the background.
+func respondCreatedSong(c *gin.Context, song Song) {
+	if song.EnrichmentPending {
+		go finishEnrichment(song.ID)
 	}
-	db.Create(&song)
-	c.JSON(http.StatusCreated, song)
+	localizeSong(c, &song)
+	respondJSON(c, http.StatusCreated, song)
 }
 
 // @Summary Delete a song
 // @Description Delete a song by ID
-// @Param id path int true "Song ID"
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
 // @Success 200 {object} map[string]string
 // @Router /songs/{id} [delete]
 func deleteSong(c *gin.Context) {
-	id := c.Param("id")
-	db.Delete(&Song{}, id)
-	c.JSON(http.StatusOK, gin.H{"message": "Song deleted"})
+	var song Song
+	if query, ok := whereSongID(dbFrom(c), c.Param("id")); ok && query.Select("id", "uuid").Take(&song).Error == nil {
+		if dbFrom(c).Delete(&song).RowsAffected > 0 {
+			onSongChanged(song, songDeleted)
+		}
+	}
+	respondJSON(c, http.StatusOK, gin.H{"message": "Song deleted"})
 }
 
 // @Summary Update a song
 // @Description Update details of an existing song by ID
 // @Accept json
 // @Produce json
-// @Param id path int true "Song ID"
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param Accept-Language header string false "Locale used to format release dates, in which they are also accepted"
 // @Param song body Song true "Updated Song Data"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
+// @Param X-Actor header string false "Who is making the change, recorded in the song history"
+// @Param If-Match header string true "ETag the update is conditional on"
 // @Success 200 {object} Song
+// @Failure 409 {object} APIError "Another song has the same group and title"
+// @Failure 412 {object} APIError
+// @Failure 428 {object} APIError
+// @Failure 415 {object} APIError
 // @Router /songs/{id} [put]
 func updateSong(c *gin.Context) {
-	id := c.Param("id")
 	var song Song
-	if err := db.First(&song, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+	if !findSong(c, dbFrom(c), &song) {
 		return
 	}
+	if !checkIfMatch(c, song) {
+		return
+	}
+	previous := song
 
-	if err := c.ShouldBindJSON(&song); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	if err := bindSong(c, &song); err != nil {
+		respondBindError(c, err)
 		return
 	}
-	db.Save(&song)
-	c.JSON(http.StatusOK, song)
+	// The song in the path is the one updated, whatever id the body has.
+	song.ID = previous.ID
+	saveSongUpdate(c, previous, song, actionUpdate)
 }
 
-func main() {
+// saveSongUpdate stores song, which was loaded as previous and then changed
+// by the request, and writes the response. The song goes through the song
+// checks, a revision of previous is kept under action, and the write only
+// happens if nobody saved the song since it was loaded.
+func saveSongUpdate(c *gin.Context, previous, song Song, action string) {
+	if !validateSong(c, &song) {
+		return
+	}
+	link := song.Link
+	song.Link, song.LinkStatus, song.LinkCheckedAt = previous.Link, previous.LinkStatus, previous.LinkCheckedAt
+	err := dbFrom(c).Transaction(func(tx *gorm.DB) error {
+		if err := saveRevisions(tx, []Song{previous}, actorFrom(c), action); err != nil {
+			return err
+		}
+		// Only write if nobody else saved the song since it was loaded. Plays
+		// don't bump updated_at, so the play count is left to POST /play.
+		result := tx.Model(&song).Where("updated_at = ?", previous.UpdatedAt).Select("*").
+			Omit(clause.Associations, "play_count", "link", "link_status", "link_checked_at").Updates(&song)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return errSongModified
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		return setPrimaryLink(tx, &song, link)
+	})
+	if errors.Is(err, errSongModified) {
+		respondError(c, http.StatusPreconditionFailed, codePreconditionFailed, "Song has been modified since it was fetched")
+		return
+	}
+	if conflict, ok := asConflict(err); ok {
+		respondConflict(c, conflict)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to update song")
+		return
+	}
+	c.Header("ETag", songETag(song))
+	onSongChanged(song, changeTypeFor(previous.Group, song.Group))
+	localizeSong(c, &song)
+	respondJSON(c, http.StatusOK, song)
+}
+
+// validateSong runs the input checks shared by create and update. It writes
+// the error response and returns false when song is rejected.
+func validateSong(c *gin.Context, song *Song) bool {
+	if err := checkSong(song, requestCheckOptions(c)); err != nil {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, err.Error())
+		return false
+	}
+	return true
+}
+
+// requestCheckOptions takes the check options from the request: ?truncate=
+// and warnings sent back as Warning headers.
+func requestCheckOptions(c *gin.Context) checkOptions {
+	return checkOptions{
+		truncate:   c.Query("truncate") == "true",
+		warn:       func(message string) { addWarning(c, message) },
+		dateLayout: dateLayoutFor(c.GetHeader("Accept-Language")),
+	}
+}
+
+// createSong enriches, checks and stores a new song. It returns an
+// invalidSongError if the song is rejected.
+func createSong(ctx context.Context, tx *gorm.DB, song *Song, opts checkOptions) error {
+	// The database assigns the id; one from the body would overwrite or
+	// collide with an existing song.
+	song.ID = 0
+	enrichSong(ctx, song)
+	if err := checkSong(song, opts); err != nil {
+		return err
+	}
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		// Links are only written through setPrimaryLink.
+		if err := tx.Omit(clause.Associations).Create(song).Error; err != nil {
+			return err
+		}
+		return setPrimaryLink(tx, song, song.Link)
+	})
+	if err != nil {
+		return err
+	}
+	onSongChanged(*song, songCreated)
+	return nil
+}
+
+// invalidSongError is the reason a song is rejected, phrased for the client.
+type invalidSongError string
+
+func (e invalidSongError) Error() string { return string(e) }
+
+// checkOptions are the settings of a check that come from the caller rather
+// than the song.
+type checkOptions struct {
+	// truncate cuts text that is too long down instead of rejecting it.
+	truncate bool
+	// warn reports a problem that doesn't reject the song.
+	warn func(message string)
+	// dateLayout is the layout release dates are localized to for the
+	// client, accepted on input besides releaseDateInputLayouts.
+	dateLayout string
+}
+
+// songChecks normalize a song in place and return an invalidSongError if it
+// is rejected. They run in order, so later checks see normalized values.
+var songChecks = []func(*Song, checkOptions) error{
+	checkNameLength,
+	checkTextLength,
+	checkReleaseDate,
+	checkFutureReleaseDate,
+	checkCoverURL,
+	checkProfanity,
+}
+
+// checkSong runs songChecks on song, stopping at the first rejection.
+func checkSong(song *Song, opts checkOptions) error {
+	for _, check := range songChecks {
+		if err := check(song, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// songLengthLimits are the configured maximum lengths in characters of the
+// song fields that have one, as enforced by the checks and published by
+// GET /songs/schema.
+func songLengthLimits() map[string]int {
+	return map[string]int{"group": cfg.MaxNameLength, "song": cfg.MaxNameLength, "text": cfg.MaxTextLength}
+}
+
+// checkNameLength rejects a group or title longer than cfg.MaxNameLength.
+func checkNameLength(song *Song, _ checkOptions) error {
+	limits := songLengthLimits()
+	for field, value := range map[string]string{"group": song.Group, "song": song.Song} {
+		if utf8.RuneCountInString(value) > limits[field] {
+			return invalidSongError(fmt.Sprintf("%s exceeds maximum length of %d characters", field, limits[field]))
+		}
+	}
+	return nil
+}
+
+// checkTextLength applies the text length limit to song, truncating the text
+// instead of rejecting it when the caller asks for it.
+func checkTextLength(song *Song, opts checkOptions) error {
+	text, ok := limitText(song.Text, opts.truncate)
+	if !ok {
+		return invalidSongError(fmt.Sprintf("Text exceeds maximum length of %d characters", cfg.MaxTextLength))
+	}
+	song.Text = text
+	return nil
+}
+
+// checkReleaseDate normalizes the release date of song to the canonical
+// format, rejecting it if the date can't be parsed.
+func checkReleaseDate(song *Song, opts checkOptions) error {
+	date, ok := normalizeReleaseDate(song.ReleaseDate, opts.dateLayout)
+	if !ok {
+		return invalidSongError("Invalid release_date, expected YYYY-MM-DD")
+	}
+	song.ReleaseDate = date
+	return nil
+}
+
+// setup loads the configuration and connects to the database, exiting if
+// either fails.
+func setup() {
 	if err := godotenv.Load(); err != nil {
 		logrus.Warn("No .env file found")
 	} else {
 		logrus.Info(".env file loaded")
 	}
 
+	cfg = loadConfig()
+	if errs := cfg.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			logrus.Errorf("Invalid configuration: %v", err)
+		}
+		logrus.Fatal("Refusing to start with invalid configuration")
+	}
 	initDB()
+	enricher = newEnricher(cfg)
+	enrichmentLimiter = newRateLimiter(cfg.EnrichmentRPS)
+	enrichmentSlots = newSemaphore(cfg.EnrichmentConcurrency)
+	maintenanceMode.Store(cfg.MaintenanceMode)
+}
+
+// newEngine creates the router with the redirect options from cfg.
+func newEngine() *gin.Engine {
+	r := gin.New()
+	r.RedirectTrailingSlash = cfg.RedirectTrailingSlash
+	r.RedirectFixedPath = cfg.CaseInsensitiveRoutes
+	return r
+}
 
-	r := gin.Default()
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
 
-	r.GET("/songs", getSongs)
+	seed := flag.Bool("seed", false, "insert sample songs into an empty database")
+	flag.Parse()
+
+	setup()
+	if *seed || cfg.SeedOnStart {
+		seedDB()
+	}
+	failInterruptedImportJobs()
+
+	r := newEngine()
+	if len(cfg.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			logrus.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+		}
+	}
+	r.Use(gin.Logger(), recovery())
+	r.Use(securityHeaders())
+	r.Use(compressResponses(cfg.Compression))
+	r.Use(limitInFlight(cfg.MaxInFlight, cfg.MaxQueued))
+	r.Use(requestTimeout(cfg.RequestTimeout))
+	r.Use(blockWritesInMaintenance())
+	r.Use(strictQueryParams(cfg.StrictQueryParams))
+
+	r.GET("/songs", deprecatedParam("offset", "The offset parameter is deprecated, narrow the results with filters instead", cfg.OffsetSunset), getSongs)
+	r.GET("/songs/recent", requireFeature("recent"), getRecentSongs)
+	r.GET("/songs/popular", requireFeature("popular"), getPopularSongs)
+	r.GET("/songs/longest", requireFeature("longest"), getLongestSongs)
+	r.GET("/songs/trending", requireFeature("trending"), getTrendingSongs)
+	r.GET("/songs/incomplete", requireFeature("incomplete"), getIncompleteSongs)
+	r.GET("/songs/exists", getSongExists)
+	r.GET("/songs/lookup", lookupSong)
+	r.GET("/songs/schema", getSongSchema)
+	r.GET("/songs/suggest", requireFeature("suggest"), getSongSuggestions)
+	r.GET("/songs/index", getSongIndex)
+	r.GET("/songs/export.ndjson", exportSongs)
+	r.GET("/songs/stream", requireFeature("stream"), streamSongChanges)
+	r.GET("/songs/:id", getSong)
 	r.GET("/songs/:id/lyrics", getSongLyrics)
-	r.POST("/songs", addSong)
+	r.PUT("/songs/:id/lyrics", requireJSON(), putSongLyrics)
+	r.PATCH("/songs/:id/lyrics", requireJSON(), putSongLyrics)
+	r.GET("/songs/:id/lyrics/full", getFullLyrics)
+	r.GET("/songs/:id/diff", requireFeature("diff"), getSongDiff)
+	r.GET("/songs/:id/card", requireFeature("card"), getSongCard)
+	r.POST("/songs", requireJSON(), addSong)
+	r.POST("/songs/echo", requireFeature("echo"), requireJSON(), echoSong)
+	r.PUT("/songs", requireJSON(), createSongIfNotExists)
+	r.POST("/songs/enrich", requireJSON(), bulkEnrichSongs)
+	r.POST("/songs/lyrics/batch", requireJSON(), getLyricsBatch)
+	r.POST("/songs/import/jobs", requireFeature("import_jobs"), startImportJob)
+	r.GET("/songs/import/jobs/:id", requireFeature("import_jobs"), getImportJob)
 	r.DELETE("/songs/:id", deleteSong)
-	r.PUT("/songs/:id", updateSong)
+	r.PUT("/songs/:id", requireJSON(), updateSong)
+	r.PATCH("/songs", requireJSON(), bulkUpdateSongs)
+	r.POST("/songs/:id/links", requireJSON(), addSongLink)
+	r.DELETE("/songs/:id/links/:linkID", deleteSongLink)
+	r.GET("/songs/:id/history", getSongHistory)
+	r.POST("/songs/:id/revert/:revisionID", revertSong)
+	r.POST("/songs/:id/normalize-lyrics", normalizeSongLyrics)
+	r.POST("/songs/:id/play", playSong)
+	r.GET("/songs/:id/neighbors", requireFeature("neighbors"), getSongNeighbors)
+	r.GET("/songs/:id/lyric-similar", requireFeature("lyric_similar"), getLyricSimilarSongs)
+	r.GET("/songs/:id/translations", getSongTranslations)
+	r.POST("/songs/:id/translations", requireJSON(), addSongTranslation)
+
+	r.GET("/groups", getGroups)
+	r.GET("/groups/top", getTopGroups)
+	r.GET("/groups/:group/stats", getGroupStats)
+	r.GET("/groups/:group/years", getGroupYears)
+
+	r.POST("/admin/check-links", startLinkCheck)
+	r.GET(maintenancePath, getMaintenance)
+	r.POST(maintenancePath, requireJSON(), setMaintenance)
+	r.GET("/admin/duplicates", getDuplicates)
+	r.GET("/admin/audit", getAuditLog)
+	r.POST("/admin/recompute-lyric-stats", startLyricStatsRecompute)
+
+	r.GET("/verses/random", requireFeature("random_verse"), getRandomVerse)
+	r.GET("/digest", requireFeature("digest"), getDigest)
+	r.GET("/stats/completeness", requireFeature("completeness"), getCompleteness)
+	r.GET("/lyrics/search", requireFeature("lyrics_search"), searchLyrics)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/openapi.json", getOpenAPISpec)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-	logrus.Infof("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
+	logrus.Infof("Server starting on port %s", cfg.Port)
+	if err := r.Run(":" + cfg.Port); err != nil {
 		logrus.Fatalf("Error starting server: %v", err)
 	}
 }
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAddSongIgnoresBodyID(t *testing.T) {
+	testDB(t)
+	withIDType(t, idTypeInt)
+	existing := Song{Group: "Queen", Song: "Innuendo"}
+	if err := db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/songs", addSong)
+	for _, id := range []string{`1`, `"1"`} {
+		w := httptest.NewRecorder()
+		body := `{"id": ` + id + `, "group": "Muse", "song": "Uprising"}`
+		req := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+		}
+		var created Song
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == existing.ID {
+			t.Errorf("created song has the id %d from the body", created.ID)
+		}
+	}
+
+	var song Song
+	if err := db.First(&song, existing.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if song.Group != "Queen" || song.Song != "Innuendo" {
+		t.Errorf("existing song = %q by %q, want it untouched", song.Song, song.Group)
+	}
+}
+
+func TestGetSongsHasLink(t *testing.T) {
+	testDB(t)
+	for title, link := range map[string]string{
+		"Linked":   "https://example.com/linked",
+		"Insecure": "HTTP://example.com/insecure",
+		"Broken":   "example.com/broken",
+		"Unlinked": "",
+	} {
+		if err := db.Create(&Song{Group: "Queen", Song: title, Link: link}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?has_link=true", []string{"Insecure", "Linked"}},
+		{"?has_link=false", []string{"Broken", "Unlinked"}},
+		{"", []string{"Broken", "Insecure", "Linked", "Unlinked"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := songTitles(fetchSongs(t, getSongs, tt.query))
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("songs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSongsHasText(t *testing.T) {
+	testDB(t)
+	for title, text := range map[string]string{
+		"Lyrics":       "While the sun hangs in the sky",
+		"Instrumental": "",
+		"Blank":        " \n\t\n",
+	} {
+		if err := db.Create(&Song{Group: "Queen", Song: title, Text: text}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?has_text=true", []string{"Lyrics"}},
+		{"?has_text=false", []string{"Blank", "Instrumental"}},
+		{"?has_text=maybe", []string{"Blank", "Instrumental", "Lyrics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := songTitles(fetchSongs(t, getSongs, tt.query))
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("songs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcludedGroups(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"exclude_group=Queen", []string{"Queen"}},
+		{"exclude_group=Queen,%20Yes%20,,Led%20Zeppelin", []string{"Queen", "Yes", "Led Zeppelin"}},
+		{"exclude_group=,%20,", nil},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil)
+		if got := excludedGroups(c); !slices.Equal(got, tt.want) {
+			t.Errorf("excludedGroups(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestGetSongsExcludeGroup(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Yes", Song: "Roundabout"},
+		{Group: "Led Zeppelin", Song: "Kashmir"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := songTitles(fetchSongs(t, getSongs, "?exclude_group=Queen,%20Led%20Zeppelin"))
+	if want := []string{"Roundabout"}; !slices.Equal(got, want) {
+		t.Errorf("songs = %v, want %v", got, want)
+	}
+}
+
+func TestGetSongsConditionalGet(t *testing.T) {
+	testDB(t)
+	if err := db.Create(&Song{Group: "Queen", Song: "Innuendo"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/songs", getSongs)
+	get := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/songs", nil)
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	lastModified := w.Header().Get("Last-Modified")
+	if w.Code != http.StatusOK || lastModified == "" {
+		t.Fatalf("status = %d, Last-Modified = %q, want %d with a Last-Modified", w.Code, lastModified, http.StatusOK)
+	}
+	if code := get(lastModified).Code; code != http.StatusNotModified {
+		t.Errorf("status with a current copy = %d, want %d", code, http.StatusNotModified)
+	}
+	modified, _ := http.ParseTime(lastModified)
+	if code := get(modified.Add(-time.Second).Format(http.TimeFormat)).Code; code != http.StatusOK {
+		t.Errorf("status with a stale copy = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestGormLogger(t *testing.T) {
+	var out strings.Builder
+	logrus.SetOutput(&out)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	query := func() (string, int64) { return "SELECT 42", 1 }
+	tests := []struct {
+		level   string
+		elapsed time.Duration
+		logged  bool
+	}{
+		{"info", 0, true},
+		{"warn", 0, false},
+		{"warn", time.Second, true},
+		{"error", time.Second, false},
+		{"silent", time.Second, false},
+		{"unknown", time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.level, tt.elapsed), func(t *testing.T) {
+			out.Reset()
+			gormLogger(tt.level).Trace(context.Background(), time.Now().Add(-tt.elapsed), query, nil)
+			if logged := strings.Contains(out.String(), "SELECT 42"); logged != tt.logged {
+				t.Errorf("query logged = %v, want %v: %s", logged, tt.logged, out.String())
+			}
+		})
+	}
+}
+
+func TestCheckNameLength(t *testing.T) {
+	previous := cfg
+	cfg.MaxNameLength = 5
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		name    string
+		song    Song
+		wantErr string
+	}{
+		{"at the limit", Song{Group: "Queen", Song: "Yes"}, ""},
+		{"multi-byte runes", Song{Group: "Björk", Song: "Jóga"}, ""},
+		{"group too long", Song{Group: "Queens", Song: "Yes"}, "group exceeds maximum length of 5 characters"},
+		{"song too long", Song{Group: "Yes", Song: "Innuendo"}, "song exceeds maximum length of 5 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkNameLength(&tt.song, checkOptions{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkNameLength() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("checkNameLength() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEngineRedirects(t *testing.T) {
+	tests := []struct {
+		name            string
+		trailingSlash   bool
+		caseInsensitive bool
+		method          string
+		path            string
+		wantStatus      int
+		wantLocation    string
+	}{
+		{"trailing slash", true, false, http.MethodGet, "/songs/", http.StatusMovedPermanently, "/songs"},
+		{"trailing slash on post", true, false, http.MethodPost, "/songs/", http.StatusTemporaryRedirect, "/songs"},
+		{"trailing slash off", false, false, http.MethodGet, "/songs/", http.StatusNotFound, ""},
+		{"case off", true, false, http.MethodGet, "/Songs/Abc", http.StatusNotFound, ""},
+		{"case on", true, true, http.MethodGet, "/Songs/Abc", http.StatusMovedPermanently, "/songs/Abc"},
+		{"exact", false, false, http.MethodGet, "/songs", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := cfg
+			cfg.RedirectTrailingSlash, cfg.CaseInsensitiveRoutes = tt.trailingSlash, tt.caseInsensitive
+			t.Cleanup(func() { cfg = previous })
+
+			r := newEngine()
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			r.GET("/songs", ok)
+			r.POST("/songs", ok)
+			r.GET("/songs/:id", ok)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
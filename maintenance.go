@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maintenancePath = "/admin/maintenance"
+
+// maintenanceMode blocks writes while set. It starts out as MAINTENANCE_MODE
+// and can be toggled at runtime through POST /admin/maintenance.
+var maintenanceMode atomic.Bool
+
+// maintenanceRetryAfter is the Retry-After sent for writes blocked by
+// maintenance mode, in seconds.
+const maintenanceRetryAfter = 60
+
+// blockWritesInMaintenance rejects mutating requests with a 503 while
+// maintenance mode is on, except for the request turning it off.
+func blockWritesInMaintenance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if maintenanceMode.Load() && c.Request.URL.Path != maintenancePath {
+				c.Abort()
+				c.Header("Retry-After", strconv.Itoa(maintenanceRetryAfter))
+				respondError(c, http.StatusServiceUnavailable, codeUnavailable, "The library is in maintenance mode, writes are disabled")
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+type MaintenanceStatus struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// @Summary Get maintenance mode
+// @Produce json
+// @Success 200 {object} MaintenanceStatus
+// @Router /admin/maintenance [get]
+func getMaintenance(c *gin.Context) {
+	enabled := maintenanceMode.Load()
+	respondJSON(c, http.StatusOK, MaintenanceStatus{Enabled: &enabled})
+}
+
+// @Summary Toggle maintenance mode
+// @Description Turn maintenance mode on or off. While it is on, every request that isn't a read gets a 503.
+// @Accept json
+// @Produce json
+// @Param status body MaintenanceStatus true "Whether maintenance mode is on"
+// @Success 200 {object} MaintenanceStatus
+// @Router /admin/maintenance [post]
+func setMaintenance(c *gin.Context) {
+	var status MaintenanceStatus
+	if err := c.ShouldBindJSON(&status); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	maintenanceMode.Store(*status.Enabled)
+	respondJSON(c, http.StatusOK, status)
+}
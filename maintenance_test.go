@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func withMaintenanceMode(t *testing.T, enabled bool) {
+	t.Helper()
+	previous := maintenanceMode.Load()
+	maintenanceMode.Store(enabled)
+	t.Cleanup(func() { maintenanceMode.Store(previous) })
+}
+
+func maintenanceRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(blockWritesInMaintenance())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/songs", ok)
+	r.HEAD("/songs", ok)
+	r.POST("/songs", ok)
+	r.PUT("/songs/:id", ok)
+	r.PATCH("/songs", ok)
+	r.DELETE("/songs/:id", ok)
+	r.GET(maintenancePath, getMaintenance)
+	r.POST(maintenancePath, setMaintenance)
+	return r
+}
+
+func TestMaintenanceModeBlocksWrites(t *testing.T) {
+	withMaintenanceMode(t, true)
+	r := maintenanceRouter()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/songs", http.StatusOK},
+		{http.MethodHead, "/songs", http.StatusOK},
+		{http.MethodGet, maintenancePath, http.StatusOK},
+		{http.MethodPost, "/songs", http.StatusServiceUnavailable},
+		{http.MethodPut, "/songs/1", http.StatusServiceUnavailable},
+		{http.MethodPatch, "/songs", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/songs/1", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("no Retry-After on the blocked write")
+			}
+		})
+	}
+}
+
+func TestMaintenanceModeOff(t *testing.T) {
+	withMaintenanceMode(t, false)
+	w := httptest.NewRecorder()
+	maintenanceRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/songs", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestMaintenanceModeCanBeTurnedOff(t *testing.T) {
+	withMaintenanceMode(t, true)
+	r := maintenanceRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, maintenancePath, strings.NewReader(`{"enabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if maintenanceMode.Load() {
+		t.Fatal("maintenance mode still on")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/songs", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status of a write after turning it off = %d, want %d", w.Code, http.StatusOK)
+	}
+}
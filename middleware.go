@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireJSON rejects requests whose body is not declared as JSON.
+func requireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.ContentType() != gin.MIMEJSON {
+			c.Abort()
+			respondError(c, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+		c.Next()
+	}
+}
+
+// longLivedRoutes are routes whose requests stay open until the client
+// leaves. They are exempt from the request timeout and in-flight limit.
+var longLivedRoutes = map[string]bool{
+	"/songs/stream": true,
+}
+
+// requestTimeout gives every request a deadline of timeout. Handlers that
+// run past it get a 504 instead of whatever they were about to respond.
+// A timeout of zero disables the deadline.
+func requestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || longLivedRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if timedOut(c) && !c.Writer.Written() {
+			respondError(c, http.StatusGatewayTimeout, codeTimeout, "Request timed out")
+		}
+	}
+}
+
+func timedOut(c *gin.Context) bool {
+	return errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
+
+// limitInFlight lets at most maxInFlight requests run at once. Up to
+// maxQueued more wait for a slot; beyond that requests get a 503 right away,
+// so a spike can't pile up goroutines and database connections. A limit of
+// zero disables it.
+func limitInFlight(maxInFlight, maxQueued int) gin.HandlerFunc {
+	if maxInFlight <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	slots := make(chan struct{}, maxInFlight)
+	var queued atomic.Int64
+	return func(c *gin.Context) {
+		if longLivedRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			if queued.Add(1) > int64(maxQueued) {
+				queued.Add(-1)
+				rejectOverloaded(c)
+				return
+			}
+			select {
+			case slots <- struct{}{}:
+				queued.Add(-1)
+			case <-c.Request.Context().Done():
+				queued.Add(-1)
+				rejectOverloaded(c)
+				return
+			}
+		}
+		defer func() { <-slots }()
+		c.Next()
+	}
+}
+
+func rejectOverloaded(c *gin.Context) {
+	c.Abort()
+	c.Header("Retry-After", "1")
+	respondError(c, http.StatusServiceUnavailable, codeUnavailable, "Server is overloaded, try again later")
+}
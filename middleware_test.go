@@ -0,0 +1,152 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// blockingRouter serves /slow through limitInFlight, holding each request
+// until release is closed. entered receives a value once a request runs.
+func blockingRouter(maxInFlight, maxQueued int) (r *gin.Engine, entered chan struct{}, release chan struct{}) {
+	entered, release = make(chan struct{}, 8), make(chan struct{})
+	r = gin.New()
+	r.Use(limitInFlight(maxInFlight, maxQueued))
+	r.GET("/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r, entered, release
+}
+
+func serve(r http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestLimitInFlightRejectsWhenFull(t *testing.T) {
+	r, entered, release := blockingRouter(1, 0)
+	first := make(chan int)
+	go func() { first <- serve(r, "/slow").Code }()
+	<-entered
+
+	w := serve(r, "/fast")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After on the rejected request")
+	}
+
+	close(release)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("status of the running request = %d, want %d", code, http.StatusOK)
+	}
+	if code := serve(r, "/fast").Code; code != http.StatusOK {
+		t.Errorf("status once the slot is free = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestLimitInFlightQueues(t *testing.T) {
+	r, entered, release := blockingRouter(1, 1)
+	codes := make(chan int, 2)
+	go func() { codes <- serve(r, "/slow").Code }()
+	<-entered
+	// The second request waits for the slot instead of being rejected.
+	go func() { codes <- serve(r, "/slow").Code }()
+
+	close(release)
+	for range 2 {
+		if code := <-codes; code != http.StatusOK {
+			t.Errorf("status = %d, want %d", code, http.StatusOK)
+		}
+	}
+}
+
+func TestLimitInFlightExemptsLongLivedRoutes(t *testing.T) {
+	r, entered, release := blockingRouter(1, 0)
+	r.GET("/songs/stream", func(c *gin.Context) { c.Status(http.StatusOK) })
+	done := make(chan struct{})
+	go func() {
+		serve(r, "/slow")
+		close(done)
+	}()
+	<-entered
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	if code := serve(r, "/songs/stream").Code; code != http.StatusOK {
+		t.Errorf("status = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestRequireJSON(t *testing.T) {
+	r := gin.New()
+	r.POST("/songs", requireJSON(), func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	tests := []struct {
+		contentType string
+		want        int
+	}{
+		{"application/json", http.StatusCreated},
+		{"application/json; charset=utf-8", http.StatusCreated},
+		{"", http.StatusUnsupportedMediaType},
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(`{}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	r := gin.New()
+	r.Use(requestTimeout(20 * time.Millisecond))
+	// slow waits for the deadline like a cancelled query would, then tries
+	// to respond anyway.
+	r.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		respondJSON(c, http.StatusOK, gin.H{"message": "too late"})
+	})
+	r.GET("/fast", func(c *gin.Context) { respondJSON(c, http.StatusOK, gin.H{"message": "ok"}) })
+	r.GET("/songs/stream", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/slow", http.StatusGatewayTimeout},
+		{"/fast", http.StatusOK},
+		{"/songs/stream", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := serve(r, tt.path).Code; code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.path, code, tt.want)
+		}
+	}
+}
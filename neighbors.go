@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Neighbors struct {
+	Previous *SongRef `json:"previous" swaggertype:"string"`
+	Next     *SongRef `json:"next" swaggertype:"string"`
+}
+
+// keysetCondition returns the condition matching songs after the song @id
+// in the order of keys. The values of that song are read in subqueries, so
+// collations apply to both sides of each comparison.
+func keysetCondition(keys []sortKey) string {
+	current := func(column string) string {
+		return fmt.Sprintf("(SELECT %s FROM songs AS current WHERE current.id = @id)", column)
+	}
+
+	var alternatives []string
+	for i, key := range keys {
+		var terms []string
+		for _, equal := range keys[:i] {
+			terms = append(terms, fmt.Sprintf("%s = %s", equal.column, current(equal.column)))
+		}
+		op := ">"
+		if key.desc {
+			op = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", key.column, op, current(key.column)))
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return strings.Join(alternatives, " OR ")
+}
+
+// neighbor returns the reference to the first song matching the listing filters
+// that comes after id in the order of keys, or nil if there is none.
+func neighbor(c *gin.Context, id uint, keys []sortKey) (*SongRef, error) {
+	query := filterSongs(c, dbFrom(c).Model(&Song{})).
+		Where(keysetCondition(keys), map[string]any{"id": id})
+	for _, key := range keys {
+		query = query.Order(key.order())
+	}
+	var song Song
+	err := query.Select("id", "uuid").Take(&song).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	ref := songRef(song)
+	return &ref, err
+}
+
+// @Summary Get neighboring songs
+// @Description Get the IDs, or UUIDs with ID_TYPE=uuid, of the songs before and after a song in a listing with the given sort order and filters, or null at either end
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param sort query string false "Sort fields as in the listing, e.g. group,-release_date"
+// @Param locale query string false "Locale for sorting text fields"
+// @Param group query string false "Filter by group"
+// @Param song query string false "Filter by song"
+// @Param exclude_group query string false "Comma-separated groups to leave out (at most 50)"
+// @Param safe query bool false "Exclude flagged songs"
+// @Param has_link query bool false "Only songs with (true) or without (false) a valid link"
+// @Param has_cover query bool false "Only songs with (true) or without (false) a cover"
+// @Param has_text query bool false "Only songs with (true) or without (false) lyrics"
+// @Param link_status query string false "Only songs whose link was last checked as ok, broken or unknown"
+// @Param updated_since query string false "Only songs updated since this RFC 3339 time, or since the start of this YYYY-MM-DD date in DEFAULT_TIMEZONE"
+// @Success 200 {object} Neighbors
+// @Router /songs/{id}/neighbors [get]
+func getSongNeighbors(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c).Select("id"), &song) || !checkSongFilters(c) {
+		return
+	}
+	keys, ok := parseSort(c)
+	if !ok {
+		return
+	}
+	// The listing breaks ties by id, and so do the neighbors.
+	keys = append(keys, sortKey{column: "id"})
+	backwards := make([]sortKey, len(keys))
+	for i, key := range keys {
+		backwards[i] = sortKey{column: key.column, desc: !key.desc}
+	}
+
+	var neighbors Neighbors
+	var err error
+	if neighbors.Previous, err = neighbor(c, song.ID, backwards); err == nil {
+		neighbors.Next, err = neighbor(c, song.ID, keys)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get neighbors")
+		return
+	}
+	respondJSON(c, http.StatusOK, neighbors)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	got := keysetCondition([]sortKey{{column: "song", desc: true}, {column: "id"}})
+	want := "(song < (SELECT song FROM songs AS current WHERE current.id = @id))" +
+		" OR (song = (SELECT song FROM songs AS current WHERE current.id = @id)" +
+		" AND id > (SELECT id FROM songs AS current WHERE current.id = @id))"
+	if got != want {
+		t.Errorf("keysetCondition() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGetSongNeighbors(t *testing.T) {
+	withIDType(t, idTypeInt)
+	testDB(t)
+	// Ids 1 to 4; sorted by song: 3 Bicycle Race, 1 Innuendo, 4 Innuendo, 2 Roundabout.
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo"},
+		{Group: "Yes", Song: "Roundabout"},
+		{Group: "Queen", Song: "Bicycle Race"},
+		{Group: "Queen", Song: "Innuendo"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/songs/:id/neighbors", getSongNeighbors)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/songs/1/neighbors?sort=song", `{"previous":3,"next":4}`},
+		{"/songs/4/neighbors?sort=song", `{"previous":1,"next":2}`},
+		{"/songs/3/neighbors?sort=song", `{"previous":null,"next":1}`},
+		{"/songs/2/neighbors?sort=song", `{"previous":4,"next":null}`},
+		{"/songs/4/neighbors?sort=song&group=Queen", `{"previous":1,"next":null}`},
+		{"/songs/2/neighbors", `{"previous":1,"next":3}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := serve(r, tt.path)
+			var got, want any
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("status %d: %v", w.Code, err)
+			}
+			json.Unmarshal([]byte(tt.want), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("neighbors = %s, want %s", w.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	r := gin.New()
+	r.GET("/openapi.json", getOpenAPISpec)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var spec struct {
+		Swagger string                     `json:"swagger"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	if spec.Swagger == "" {
+		t.Error("spec has no swagger version")
+	}
+	for _, path := range []string{"/songs", "/songs/{id}", "/openapi.json"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("spec has no path %s", path)
+		}
+	}
+}
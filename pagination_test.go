@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPagination(t *testing.T) {
+	previous := cfg
+	cfg.MaxLimit, cfg.MaxOffset = 100, 1000
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		query      string
+		wantLimit  int
+		wantOffset int
+		wantOK     bool
+	}{
+		{"", 10, 0, true},
+		{"limit=25&offset=50", 25, 50, true},
+		{"limit=500", 100, 0, true},
+		{"limit=abc", 10, 0, true},
+		{"limit=0", 0, 0, false},
+		{"limit=-5", 0, 0, false},
+		{"offset=-1", 0, 0, false},
+		{"offset=1001", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil)
+
+			limit, offset, ok := pagination(c)
+			if limit != tt.wantLimit || offset != tt.wantOffset || ok != tt.wantOK {
+				t.Errorf("pagination() = %d, %d, %v, want %d, %d, %v", limit, offset, ok, tt.wantLimit, tt.wantOffset, tt.wantOK)
+			}
+			if !ok && w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
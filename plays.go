@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTrendingDays is the longest trending window, and how long daily play
+// counts are kept.
+const maxTrendingDays = 90
+
+// SongPlayDay counts the plays of a song on a day in DEFAULT_TIMEZONE.
+// Trending rankings sum these instead of scanning individual plays.
+type SongPlayDay struct {
+	SongID uint   `gorm:"primaryKey"`
+	Day    string `gorm:"primaryKey;type:date;index"`
+	Plays  int64  `gorm:"not null"`
+}
+
+// prunePlayDays drops daily play counts older than any trending window.
+func prunePlayDays() {
+	cutoff := time.Now().In(cfg.Location).AddDate(0, 0, -maxTrendingDays).Format(releaseDateLayout)
+	if err := db.Where("day < ?", cutoff).Delete(&SongPlayDay{}).Error; err != nil {
+		logrus.Errorf("Failed to prune play counts: %v", err)
+	}
+}
+
+// @Summary Record a play
+// @Description Increment the play count of a song. Plays aren't edits, so updated_at and the ETag stay the same.
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Success 200 {object} map[string]int64
+// @Router /songs/{id}/play [post]
+func playSong(c *gin.Context) {
+	var song Song
+	// Incrementing in SQL keeps concurrent plays from overwriting each other.
+	err := dbFrom(c).Transaction(func(tx *gorm.DB) error {
+		query, ok := whereSongID(tx.Model(&song), c.Param("id"))
+		if !ok {
+			return nil
+		}
+		result := query.Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "play_count"}}}).
+			UpdateColumn("play_count", gorm.Expr("play_count + 1"))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "song_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]any{"plays": gorm.Expr("song_play_days.plays + 1")}),
+		}).Create(&SongPlayDay{SongID: song.ID, Day: today(), Plays: 1}).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to record play")
+		return
+	}
+	if song.ID == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, "Song not found")
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"play_count": song.PlayCount})
+}
+
+type TrendingSong struct {
+	Song
+	RecentPlays int64 `json:"recent_plays"`
+}
+
+// @Summary Get trending songs
+// @Description Get the songs played most within a recent window, most plays first. Plays are counted per day in DEFAULT_TIMEZONE, so the window covers whole days including today.
+// @Produce json
+// @Param window query string false "Window in days or weeks, like 7d or 2w (default 7d, max 90d)"
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} TrendingSong
+// @Router /songs/trending [get]
+func getTrendingSongs(c *gin.Context) {
+	days, ok := parsePeriodDays(c.DefaultQuery("window", "7d"), maxTrendingDays)
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("window must be a number of days or weeks like 7d or 2w, at most %dd", maxTrendingDays))
+		return
+	}
+	limit := parseLimit(c)
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 || offset > cfg.MaxOffset {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("offset must be between 0 and %d", cfg.MaxOffset))
+		return
+	}
+
+	since := time.Now().In(cfg.Location).AddDate(0, 0, 1-days).Format(releaseDateLayout)
+	var ranking []struct {
+		SongID uint
+		Plays  int64
+	}
+	if err := dbFrom(c).Model(&SongPlayDay{}).
+		Select("song_id, sum(plays) AS plays").
+		Where("day >= ?", since).
+		Group("song_id").
+		Order("plays DESC, song_id").
+		Limit(limit).Offset(offset).
+		Scan(&ranking).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get trending songs")
+		return
+	}
+
+	ids := make([]uint, len(ranking))
+	for i, entry := range ranking {
+		ids[i] = entry.SongID
+	}
+	var songs []Song
+	if err := dbFrom(c).Preload("Links").Where("id IN ?", ids).Find(&songs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get trending songs")
+		return
+	}
+	localizeSongs(c, songs)
+	byID := make(map[uint]Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+
+	trending := []TrendingSong{}
+	for _, entry := range ranking {
+		if song, ok := byID[entry.SongID]; ok {
+			trending = append(trending, TrendingSong{Song: song, RecentPlays: entry.Plays})
+		}
+	}
+	respondJSON(c, http.StatusOK, trending)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPlaySongConcurrent(t *testing.T) {
+	testDB(t)
+	song := Song{Group: "Queen", Song: "Bohemian Rhapsody"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.POST("/songs/:id/play", playSong)
+
+	const plays = 50
+	var wg sync.WaitGroup
+	for range plays {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/songs/%d/play", song.ID), nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var stored Song
+	if err := db.Take(&stored, song.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.PlayCount != plays {
+		t.Errorf("play_count = %d, want %d", stored.PlayCount, plays)
+	}
+	var day SongPlayDay
+	if err := db.Where("song_id = ?", song.ID).Take(&day).Error; err != nil {
+		t.Fatal(err)
+	}
+	if day.Plays != plays {
+		t.Errorf("plays of today = %d, want %d", day.Plays, plays)
+	}
+}
+
+func TestGetTrendingSongsRejectsInvalidWindow(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/trending", getTrendingSongs)
+	for _, window := range []string{"0d", "91d", "13w", "week"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/trending?window="+window, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status for window=%s = %d, want %d", window, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetTrendingSongs(t *testing.T) {
+	testDB(t)
+	for _, title := range []string{"Innuendo", "Headlong", "Bicycle Race", "Demo"} {
+		if err := db.Create(&Song{Group: "Queen", Song: title}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	daysAgo := func(n int) string {
+		return time.Now().In(cfg.Location).AddDate(0, 0, -n).Format(releaseDateLayout)
+	}
+	for _, day := range []SongPlayDay{
+		{SongID: 1, Day: daysAgo(0), Plays: 2},
+		{SongID: 2, Day: daysAgo(0), Plays: 1},
+		{SongID: 2, Day: daysAgo(6), Plays: 5},
+		{SongID: 3, Day: daysAgo(7), Plays: 100},
+		{SongID: 4, Day: daysAgo(maxTrendingDays + 1), Plays: 1000},
+	} {
+		if err := db.Create(&day).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/songs/trending", getTrendingSongs)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Headlong 6", "Innuendo 2"}},
+		{"?window=8d", []string{"Bicycle Race 100", "Headlong 6", "Innuendo 2"}},
+		{"?window=2w&limit=1&offset=1", []string{"Headlong 6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/trending"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var trending []TrendingSong
+			if err := json.Unmarshal(w.Body.Bytes(), &trending); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(trending))
+			for i, song := range trending {
+				got[i] = fmt.Sprintf("%s %d", song.Song.Song, song.RecentPlays)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("trending = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	prunePlayDays()
+	var days int64
+	db.Model(&SongPlayDay{}).Where("song_id = ?", 4).Count(&days)
+	if days != 0 {
+		t.Error("prunePlayDays() kept plays older than the longest window")
+	}
+}
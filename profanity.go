@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	profanityOff    = "off"
+	profanityFlag   = "flag"
+	profanityReject = "reject"
+)
+
+func parseWordList(value string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Split(value, ",") {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// containsProfanity reports whether text contains any of the configured
+// words as a whole word, ignoring case.
+func containsProfanity(text string) bool {
+	if len(cfg.ProfanityWords) == 0 {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	for _, word := range words {
+		if cfg.ProfanityWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProfanity applies PROFANITY_MODE to song. In flag mode the song is
+// marked as flagged, in reject mode it is rejected.
+func checkProfanity(song *Song, _ checkOptions) error {
+	switch cfg.ProfanityMode {
+	case profanityFlag:
+		song.Flagged = containsProfanity(song.Text)
+	case profanityReject:
+		if containsProfanity(song.Text) {
+			return invalidSongError("Lyrics contain disallowed words")
+		}
+		song.Flagged = false
+	}
+	return nil
+}
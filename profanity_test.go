@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWordList(t *testing.T) {
+	got := parseWordList(" Darn, HECK ,,heck")
+	if want := map[string]bool{"darn": true, "heck": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWordList() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckProfanity(t *testing.T) {
+	previous := cfg
+	cfg.ProfanityWords = parseWordList("darn")
+	t.Cleanup(func() { cfg = previous })
+
+	tests := []struct {
+		name        string
+		mode        string
+		text        string
+		wantFlagged bool
+		wantErr     bool
+	}{
+		{"off", profanityOff, "Darn it", false, false},
+		{"flag", profanityFlag, "Oh DARN, it's late", true, false},
+		{"flag whole words only", profanityFlag, "darning socks", false, false},
+		{"flag clean", profanityFlag, "All is well", false, false},
+		{"reject", profanityReject, "darn!", false, true},
+		{"reject clean", profanityReject, "All is well", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.ProfanityMode = tt.mode
+			song := Song{Text: tt.text}
+			err := checkProfanity(&song, checkOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkProfanity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if song.Flagged != tt.wantFlagged {
+				t.Errorf("flagged = %v, want %v", song.Flagged, tt.wantFlagged)
+			}
+		})
+	}
+}
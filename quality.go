@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// optionalFields maps the JSON names of optional song fields to their columns.
+var optionalFields = map[string]string{
+	"cover_url":    "cover_url",
+	"release_date": "release_date",
+	"text":         "text",
+	"link":         "link",
+}
+
+type IncompleteSongs struct {
+	Counts map[string]int64 `json:"counts"`
+	Total  int64            `json:"total"`
+	Songs  []Song           `json:"songs"`
+}
+
+func missingCondition(column string) string {
+	return fmt.Sprintf("(%s IS NULL OR trim(%s) = '')", column, column)
+}
+
+// @Summary Get songs missing metadata
+// @Description Get songs lacking any of the given fields, with per-field counts
+// @Produce json
+// @Param missing query string true "Comma-separated fields: release_date, text, link, cover_url"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} IncompleteSongs
+// @Router /songs/incomplete [get]
+func getIncompleteSongs(c *gin.Context) {
+	var fields []string
+	for _, field := range strings.Split(c.Query("missing"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := optionalFields[field]; !ok {
+			respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("Unknown field %q", field))
+			return
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Missing missing parameter")
+		return
+	}
+
+	result := IncompleteSongs{Counts: make(map[string]int64), Songs: []Song{}}
+	conditions := make([]string, len(fields))
+	for i, field := range fields {
+		conditions[i] = missingCondition(optionalFields[field])
+		var count int64
+		dbFrom(c).Model(&Song{}).Where(conditions[i]).Count(&count)
+		result.Counts[field] = count
+	}
+
+	query := dbFrom(c).Model(&Song{}).Where(strings.Join(conditions, " OR ")).Session(&gorm.Session{})
+	query.Count(&result.Total)
+
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+	query.Preload("Links").Order("id").Limit(limit).Offset(offset).Find(&result.Songs)
+
+	localizeSongs(c, result.Songs)
+	respondJSON(c, http.StatusOK, result)
+}
+
+type Completeness struct {
+	Songs int64 `json:"songs"`
+	// Fields holds the percentage of songs with each optional field set.
+	Fields map[string]float64 `json:"fields"`
+	// Score is the percentage of optional fields set across all songs.
+	Score float64 `json:"score"`
+}
+
+// @Summary Get catalog completeness
+// @Description Get the percentage of songs with each optional field set, and the overall percentage of optional fields set as a completeness score
+// @Produce json
+// @Success 200 {object} Completeness
+// @Router /stats/completeness [get]
+func getCompleteness(c *gin.Context) {
+	fields := make([]string, 0, len(optionalFields))
+	for field := range optionalFields {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	columns := []string{"count(*) AS songs"}
+	for _, field := range fields {
+		columns = append(columns, fmt.Sprintf("count(*) FILTER (WHERE NOT %s) AS %s", missingCondition(optionalFields[field]), field))
+	}
+	row := make(map[string]any)
+	if err := dbFrom(c).Model(&Song{}).Select(strings.Join(columns, ", ")).Take(&row).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to compute completeness")
+		return
+	}
+
+	result := Completeness{Songs: row["songs"].(int64), Fields: make(map[string]float64, len(fields))}
+	if result.Songs > 0 {
+		var set int64
+		for _, field := range fields {
+			count := row[field].(int64)
+			set += count
+			result.Fields[field] = percentage(count, result.Songs)
+		}
+		result.Score = percentage(set, result.Songs*int64(len(fields)))
+	} else {
+		for _, field := range fields {
+			result.Fields[field] = 0
+		}
+	}
+	respondJSON(c, http.StatusOK, result)
+}
+
+// percentage returns part of whole in percent, rounded to two decimals.
+func percentage(part, whole int64) float64 {
+	return math.Round(float64(part)*10000/float64(whole)) / 100
+}
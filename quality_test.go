@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createLinkedSong stores a song without lyrics and with a primary link.
+func createLinkedSong(t *testing.T) Song {
+	t.Helper()
+	song := Song{Group: "Queen", Song: "Innuendo"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := setPrimaryLink(db, &song, "https://example.com/innuendo"); err != nil {
+		t.Fatal(err)
+	}
+	return song
+}
+
+func TestGetIncompleteSongsIncludesLinks(t *testing.T) {
+	testDB(t)
+	createLinkedSong(t)
+	r := gin.New()
+	r.GET("/songs/incomplete", getIncompleteSongs)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/incomplete?missing=text", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var result IncompleteSongs
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Songs) != 1 || len(result.Songs[0].Links) != 1 {
+		t.Fatalf("songs = %+v, want one song with its link", result.Songs)
+	}
+}
+
+func TestGetDigestIncludesLinks(t *testing.T) {
+	testDB(t)
+	createLinkedSong(t)
+	r := gin.New()
+	r.GET("/digest", getDigest)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/digest", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var digest Digest
+	if err := json.Unmarshal(w.Body.Bytes(), &digest); err != nil {
+		t.Fatal(err)
+	}
+	if len(digest.Groups) != 1 || len(digest.Groups[0].Songs) != 1 || len(digest.Groups[0].Songs[0].Links) != 1 {
+		t.Fatalf("groups = %+v, want one song with its link", digest.Groups)
+	}
+}
+
+func TestGetIncompleteSongsRejectsInvalidFields(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/incomplete", getIncompleteSongs)
+	for _, query := range []string{"", "?missing=", "?missing=,", "?missing=text,lyrics", "?missing=uuid"} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/incomplete"+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestGetIncompleteSongsCounts(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Complete", Text: "la", ReleaseDate: "1991-02-04"},
+		{Group: "Queen", Song: "No text", Text: "  ", ReleaseDate: "1991-02-04"},
+		{Group: "Queen", Song: "No date", Text: "la"},
+		{Group: "Queen", Song: "Nothing"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/songs/incomplete", getIncompleteSongs)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/incomplete?missing=text,release_date", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var result IncompleteSongs
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Counts["text"] != 2 || result.Counts["release_date"] != 2 || result.Total != 3 || len(result.Songs) != 3 {
+		t.Errorf("counts = %v, total = %d, songs = %d, want text 2, release_date 2, total 3, 3 songs",
+			result.Counts, result.Total, len(result.Songs))
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	tests := []struct {
+		part, whole int64
+		want        float64
+	}{
+		{0, 3, 0},
+		{1, 3, 33.33},
+		{2, 3, 66.67},
+		{3, 3, 100},
+		{5, 12, 41.67},
+	}
+	for _, tt := range tests {
+		if got := percentage(tt.part, tt.whole); got != tt.want {
+			t.Errorf("percentage(%d, %d) = %v, want %v", tt.part, tt.whole, got, tt.want)
+		}
+	}
+}
+
+func TestGetCompleteness(t *testing.T) {
+	testDB(t)
+	r := gin.New()
+	r.GET("/stats/completeness", getCompleteness)
+	get := func() Completeness {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats/completeness", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		var result Completeness
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	empty := get()
+	if empty.Songs != 0 || empty.Score != 0 || len(empty.Fields) != len(optionalFields) {
+		t.Errorf("completeness of an empty catalog = %+v, want zeros for every field", empty)
+	}
+
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Innuendo", ReleaseDate: "1991-02-04", Text: "la", Link: "https://example.com/innuendo", CoverURL: "https://img.example/innuendo.jpg"},
+		{Group: "Queen", Song: "Headlong", Text: "la"},
+		{Group: "Queen", Song: "Demo"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := get()
+	want := map[string]float64{"cover_url": 33.33, "release_date": 33.33, "text": 66.67, "link": 33.33}
+	if got.Songs != 3 || got.Score != 41.67 {
+		t.Errorf("songs, score = %d, %v, want 3, 41.67", got.Songs, got.Score)
+	}
+	for field, percent := range want {
+		if got.Fields[field] != percent {
+			t.Errorf("%s = %v%%, want %v%%", field, got.Fields[field], percent)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"music_library/docs"
+)
+
+// globalQueryParams are accepted by every endpoint.
+var globalQueryParams = map[string]bool{
+	"pretty": true,
+}
+
+var routeParamPattern = regexp.MustCompile(`:(\w+)`)
+
+// documentedQueryParams maps "METHOD /path" in the API specification to the
+// query parameters documented for it. The annotations on the handlers are
+// the single list of what each endpoint accepts.
+var documentedQueryParams = sync.OnceValue(func() map[string]map[string]bool {
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				In   string `json:"in"`
+				Name string `json:"name"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec); err != nil {
+		logrus.Errorf("Failed to read query parameters from the API specification: %v", err)
+	}
+	params := make(map[string]map[string]bool)
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			names := make(map[string]bool)
+			for _, param := range operation.Parameters {
+				if param.In == "query" {
+					names[param.Name] = true
+				}
+			}
+			params[strings.ToUpper(method)+" "+path] = names
+		}
+	}
+	return params
+})
+
+// strictQueryParams rejects requests with query parameters their endpoint
+// doesn't document, so a typo like ?grup= doesn't silently return every
+// song. Routes missing from the specification are let through. It is
+// enabled with STRICT_QUERY_PARAMS.
+func strictQueryParams(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		route := c.Request.Method + " " + routeParamPattern.ReplaceAllString(c.FullPath(), "{$1}")
+		known, ok := documentedQueryParams()[route]
+		if !ok {
+			c.Next()
+			return
+		}
+		var unknown []string
+		for name := range c.Request.URL.Query() {
+			if !known[name] && !globalQueryParams[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			slices.Sort(unknown)
+			c.Abort()
+			respondError(c, http.StatusBadRequest, codeInvalidInput, "Unknown query parameters: "+strings.Join(unknown, ", "))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStrictQueryParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		path       string
+		wantStatus int
+		wantError  string
+	}{
+		{"documented", true, "/songs?group=Queen&limit=5", http.StatusOK, ""},
+		{"global", true, "/songs?pretty=true", http.StatusOK, ""},
+		{"typo", true, "/songs?grup=Queen", http.StatusBadRequest, "Unknown query parameters: grup"},
+		{"several unknown", true, "/songs?b=1&group=Queen&a=2", http.StatusBadRequest, "Unknown query parameters: a, b"},
+		{"path parameter", true, "/songs/7/lyrics?page=2&per_page=5", http.StatusOK, ""},
+		{"other endpoint's parameter", true, "/songs/7/lyrics?group=Queen", http.StatusBadRequest, "Unknown query parameters: group"},
+		{"undocumented route", true, "/internal?anything=1", http.StatusOK, ""},
+		{"disabled", false, "/songs?grup=Queen", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(strictQueryParams(tt.enabled))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			r.GET("/songs", ok)
+			r.GET("/songs/:id/lyrics", ok)
+			r.GET("/internal", ok)
+
+			w := serve(r, tt.path)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantError != "" && !strings.Contains(w.Body.String(), tt.wantError) {
+				t.Errorf("body = %s, want %q", w.Body, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnrichmentConcurrencyLimit(t *testing.T) {
+	const limit, calls = 3, 12
+	var inFlight, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	previous := enrichmentSlots
+	enrichmentSlots = newSemaphore(limit)
+	t.Cleanup(func() { enrichmentSlots = previous })
+
+	var wg sync.WaitGroup
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var detail SongDetail
+			if err := getJSON(context.Background(), server.URL, &detail); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got > limit {
+		t.Errorf("peak concurrent calls = %d, want at most %d", got, limit)
+	}
+}
+
+func TestSemaphoreAcquireCancelled(t *testing.T) {
+	s := newSemaphore(1)
+	if err := s.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() on a full semaphore = %v, want %v", err, context.DeadlineExceeded)
+	}
+	s.release()
+	if err := s.acquire(context.Background()); err != nil {
+		t.Errorf("acquire() after release = %v", err)
+	}
+}
+
+func TestRateLimiterSpacesCalls(t *testing.T) {
+	const calls, interval = 5, 20 * time.Millisecond
+	l := newRateLimiter(float64(time.Second / interval))
+	start := time.Now()
+	for range calls {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed, want := time.Since(start), (calls-1)*interval; elapsed < want {
+		t.Errorf("%d calls took %v, want at least %v", calls, elapsed, want)
+	}
+}
+
+func TestRateLimiterSharedByConcurrentCallers(t *testing.T) {
+	const calls, interval = 5, 20 * time.Millisecond
+	l := newRateLimiter(float64(time.Second / interval))
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed, want := time.Since(start), (calls-1)*interval; elapsed < want {
+		t.Errorf("%d concurrent calls took %v, want at least %v", calls, elapsed, want)
+	}
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	l := newRateLimiter(1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestNilRateLimiterNeverWaits(t *testing.T) {
+	l := newRateLimiter(0)
+	if l != nil {
+		t.Fatalf("newRateLimiter(0) = %v, want nil", l)
+	}
+	if err := l.wait(context.Background()); err != nil {
+		t.Errorf("wait() on a nil limiter = %v", err)
+	}
+}
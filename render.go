@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSON field naming strategies, set with JSON_NAMING.
+const (
+	namingSnake = "snake_case"
+	namingCamel = "camelCase"
+)
+
+// respondJSON writes obj as JSON, indented when PRETTY_JSON is set or the
+// request asks for it with ?pretty=true. Once the request deadline has
+// passed, a timeout error is written instead. Errors go out as problem+json
+// to clients that ask for it.
+func respondJSON(c *gin.Context, status int, obj any) {
+	if timedOut(c) {
+		status, obj = http.StatusGatewayTimeout, APIError{Code: codeTimeout, Message: "Request timed out"}
+	}
+	if err, ok := obj.(APIError); ok && wantsProblem(c) {
+		respondProblem(c, status, err)
+		return
+	}
+	obj, err := publicJSON(obj)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if cfg.PrettyJSON || c.Query("pretty") == "true" {
+		c.IndentedJSON(status, obj)
+		return
+	}
+	c.JSON(status, obj)
+}
+
+// addVary adds field to the Vary header of the response unless it is
+// already listed, keeping what other middleware and handlers put there.
+func addVary(c *gin.Context, field string) {
+	header := c.Writer.Header()
+	for _, value := range header.Values("Vary") {
+		for _, listed := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), field) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", field)
+}
+
+// publicJSON returns obj as generic JSON values, without integer song ids
+// with ID_TYPE=uuid and with every object key converted from snake_case to
+// camelCase with JSON_NAMING=camelCase. Going through the struct tags first
+// keeps omitempty and friends working. Without either setting obj is
+// returned as is.
+func publicJSON(obj any) (any, error) {
+	if cfg.JSONNaming != namingCamel && cfg.IDType != idTypeUUID {
+		return obj, nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if cfg.IDType == idTypeUUID {
+		value = hideSongIDs(value)
+	}
+	if cfg.JSONNaming == namingCamel {
+		value = camelCaseKeys(value)
+	}
+	return value, nil
+}
+
+func camelCaseKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, inner := range v {
+			converted[snakeToCamel(key)] = camelCaseKeys(inner)
+		}
+		return converted
+	case []any:
+		for i := range v {
+			v[i] = camelCaseKeys(v[i])
+		}
+	}
+	return value
+}
+
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
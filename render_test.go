@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondJSONPretty(t *testing.T) {
+	tests := []struct {
+		name       string
+		prettyJSON bool
+		query      string
+		want       string
+	}{
+		{"compact by default", false, "", `{"group":"Queen"}`},
+		{"pretty query", false, "?pretty=true", "{\n    \"group\": \"Queen\"\n}"},
+		{"PRETTY_JSON", true, "", "{\n    \"group\": \"Queen\"\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := cfg
+			cfg.PrettyJSON = tt.prettyJSON
+			t.Cleanup(func() { cfg = previous })
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/groups"+tt.query, nil)
+			respondJSON(c, http.StatusOK, map[string]string{"group": "Queen"})
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SongRevision is a snapshot of a song taken before it was changed.
+type SongRevision struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	SongID      uint      `json:"song_id" gorm:"index;not null"`
+	Group       string    `json:"group"`
+	Song        string    `json:"song"`
+	ReleaseDate string    `json:"release_date"`
+	Text        string    `json:"text"`
+	Link        string    `json:"link"`
+	CoverURL    string    `json:"cover_url"`
+	Actor       string    `json:"actor" gorm:"index"`
+	Action      string    `json:"action" gorm:"index;not null;default:update"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+const anonymousActor = "anonymous"
+
+// The actions a revision is recorded for.
+const (
+	actionUpdate     = "update"
+	actionBulkUpdate = "bulk_update"
+	actionRevert     = "revert"
+	actionEnrich     = "enrich"
+)
+
+var revisionActions = []string{actionUpdate, actionBulkUpdate, actionRevert, actionEnrich}
+
+// actorFrom identifies who is making a change, from the X-Actor header,
+// as stored by storedActor.
+func actorFrom(c *gin.Context) string {
+	if actor := strings.TrimSpace(c.GetHeader("X-Actor")); actor != "" {
+		return storedActor(actor)
+	}
+	return anonymousActor
+}
+
+// storedActor is how actor is recorded: as is, or with AUDIT_ANONYMIZE as a
+// pseudonym keyed with AUDIT_SALT. The pseudonym is stable, so the changes
+// of one actor can still be told apart and looked up, but the identifier
+// can't be read back without the salt.
+func storedActor(actor string) string {
+	if !cfg.AuditAnonymize {
+		return actor
+	}
+	mac := hmac.New(sha256.New, []byte(cfg.AuditSalt))
+	mac.Write([]byte(actor))
+	return "anon-" + hex.EncodeToString(mac.Sum(nil))[:24]
+}
+
+func newRevision(song Song, actor, action string) SongRevision {
+	return SongRevision{
+		SongID:      song.ID,
+		Group:       song.Group,
+		Song:        song.Song,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Text,
+		Link:        song.Link,
+		CoverURL:    song.CoverURL,
+		Actor:       actor,
+		Action:      action,
+	}
+}
+
+// saveRevisions stores snapshots of songs and drops the oldest revisions of
+// each song beyond MAX_REVISIONS. action is the kind of change, one of
+// revisionActions.
+func saveRevisions(tx *gorm.DB, songs []Song, actor, action string) error {
+	if len(songs) == 0 {
+		return nil
+	}
+	revisions := make([]SongRevision, len(songs))
+	for i, song := range songs {
+		revisions[i] = newRevision(song, actor, action)
+	}
+	if err := tx.Create(&revisions).Error; err != nil {
+		return err
+	}
+
+	for _, song := range songs {
+		keep := tx.Model(&SongRevision{}).Select("id").
+			Where("song_id = ?", song.ID).Order("id DESC").Limit(cfg.MaxRevisions)
+		if err := tx.Where("song_id = ? AND id NOT IN (?)", song.ID, keep).Delete(&SongRevision{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// @Summary Get song history
+// @Description Get the stored revisions of a song, newest first
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Success 200 {array} SongRevision
+// @Router /songs/{id}/history [get]
+func getSongHistory(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+
+	revisions := []SongRevision{}
+	dbFrom(c).Where("song_id = ?", song.ID).Order("id DESC").Find(&revisions)
+	respondJSON(c, http.StatusOK, revisions)
+}
+
+// @Summary Revert a song
+// @Description Restore a song to a stored revision. The current state is kept as a new revision. The restored song goes through the same checks and preconditions as PUT /songs/{id}.
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param revisionID path int true "Revision ID"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
+// @Param X-Actor header string false "Who is making the change, recorded in the song history"
+// @Param If-Match header string true "ETag the revert is conditional on"
+// @Success 200 {object} Song
+// @Failure 400 {object} APIError "The revision ID is invalid or the revision doesn't pass the current checks"
+// @Failure 409 {object} APIError "Another song has the group and title of the revision"
+// @Failure 412 {object} APIError
+// @Failure 428 {object} APIError
+// @Router /songs/{id}/revert/{revisionID} [post]
+func revertSong(c *gin.Context) {
+	revisionID, ok := idParam(c, "revisionID", "revision")
+	if !ok {
+		return
+	}
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+	if !checkIfMatch(c, song) {
+		return
+	}
+	var revision SongRevision
+	if err := dbFrom(c).Where("id = ? AND song_id = ?", revisionID, song.ID).First(&revision).Error; err != nil {
+		respondError(c, http.StatusNotFound, codeNotFound, "Revision not found")
+		return
+	}
+
+	previous := song
+	song.Group = revision.Group
+	song.Song = revision.Song
+	song.ReleaseDate = revision.ReleaseDate
+	song.Text = revision.Text
+	song.Link = revision.Link
+	song.CoverURL = revision.CoverURL
+	saveSongUpdate(c, previous, song, actionRevert)
+}
+
+// @Summary Get changes by an actor
+// @Description Get the revisions saved when an actor changed songs, newest first. Each revision holds the song as it was before the change. Only updates, bulk updates, reverts and enrichment are recorded, and revisions beyond MAX_REVISIONS per song are pruned.
+// @Produce json
+// @Param actor query string true "Actor as sent in X-Actor, pseudonymized like stored ones with AUDIT_ANONYMIZE"
+// @Param action query string false "Only changes of this kind" Enums(update, bulk_update, revert, enrich)
+// @Param since query string false "Only changes since this RFC 3339 time or YYYY-MM-DD date"
+// @Param until query string false "Only changes before this RFC 3339 time or YYYY-MM-DD date"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} SongRevision
+// @Router /admin/audit [get]
+func getAuditLog(c *gin.Context) {
+	actor := strings.TrimSpace(c.Query("actor"))
+	if actor == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Missing actor parameter")
+		return
+	}
+	query := dbFrom(c).Where("actor = ?", storedActor(actor))
+	if action := c.Query("action"); action != "" {
+		if !slices.Contains(revisionActions, action) {
+			respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid action, expected one of "+strings.Join(revisionActions, ", "))
+			return
+		}
+		query = query.Where("action = ?", action)
+	}
+	for param, condition := range map[string]string{"since": "created_at >= ?", "until": "created_at < ?"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, ok := parseUpdatedSince(value)
+		if !ok {
+			respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid "+param+", expected an RFC 3339 time or YYYY-MM-DD")
+			return
+		}
+		query = query.Where(condition, t)
+	}
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	revisions := []SongRevision{}
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&revisions).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to load audit log")
+		return
+	}
+	respondJSON(c, http.StatusOK, revisions)
+}
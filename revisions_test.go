@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditLogFilters(t *testing.T) {
+	testDB(t)
+	song := Song{Group: "Queen", Song: "Innuendo"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, revision := range []struct{ actor, action string }{
+		{"alice", actionUpdate},
+		{"alice", actionRevert},
+		{"bob", actionUpdate},
+		{"alice", actionBulkUpdate},
+	} {
+		if err := saveRevisions(db, []Song{song}, storedActor(revision.actor), revision.action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/admin/audit", getAuditLog)
+
+	tests := []struct {
+		query       string
+		wantStatus  int
+		wantActions []string
+	}{
+		{"actor=alice", http.StatusOK, []string{actionBulkUpdate, actionRevert, actionUpdate}},
+		{"actor=bob", http.StatusOK, []string{actionUpdate}},
+		{"actor=alice&action=update", http.StatusOK, []string{actionUpdate}},
+		{"actor=carol", http.StatusOK, []string{}},
+		{"actor=alice&action=create", http.StatusBadRequest, nil},
+		{"", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit?"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantActions == nil {
+				return
+			}
+			var revisions []SongRevision
+			if err := json.Unmarshal(w.Body.Bytes(), &revisions); err != nil {
+				t.Fatal(err)
+			}
+			actions := []string{}
+			for _, revision := range revisions {
+				actions = append(actions, revision.Action)
+			}
+			if !slices.Equal(actions, tt.wantActions) {
+				t.Errorf("actions = %v, want %v", actions, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestRevertSongRejectsNonNumericRevisionID(t *testing.T) {
+	r := gin.New()
+	r.POST("/songs/:id/revert/:revisionID", revertSong)
+
+	for _, revisionID := range []string{"song_id%3C%3E1", "1%20OR%201=1", "abc"} {
+		t.Run(revisionID, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/songs/1/revert/"+revisionID, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestStoredActor(t *testing.T) {
+	previous := cfg
+	t.Cleanup(func() { cfg = previous })
+
+	cfg.AuditAnonymize, cfg.AuditSalt = false, "pepper"
+	if got := storedActor("alice"); got != "alice" {
+		t.Errorf("storedActor() without AUDIT_ANONYMIZE = %q, want alice", got)
+	}
+
+	cfg.AuditAnonymize = true
+	alice := storedActor("alice")
+	if !strings.HasPrefix(alice, "anon-") || len(alice) != len("anon-")+24 || strings.Contains(alice, "alice") {
+		t.Errorf("storedActor() = %q, want an anon- pseudonym", alice)
+	}
+	if got := storedActor("alice"); got != alice {
+		t.Errorf("storedActor() = %q, then %q, want a stable pseudonym", alice, got)
+	}
+	if storedActor("bob") == alice {
+		t.Error("storedActor() gave alice and bob the same pseudonym")
+	}
+	cfg.AuditSalt = "salt"
+	if storedActor("alice") == alice {
+		t.Error("storedActor() ignored AUDIT_SALT")
+	}
+}
+
+func TestActorFrom(t *testing.T) {
+	previous := cfg
+	cfg.AuditAnonymize = false
+	t.Cleanup(func() { cfg = previous })
+
+	for header, want := range map[string]string{"": anonymousActor, "  ": anonymousActor, " alice ": "alice"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPut, "/songs/1", nil)
+		c.Request.Header.Set("X-Actor", header)
+		if got := actorFrom(c); got != want {
+			t.Errorf("actorFrom(X-Actor: %q) = %q, want %q", header, got, want)
+		}
+	}
+}
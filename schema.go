@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FieldSchema struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Required    bool              `json:"required"`
+	ReadOnly    bool              `json:"read_only"`
+	Constraints map[string]string `json:"constraints,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaType(t reflect.Type) string {
+	if t == timeType {
+		return "datetime"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// modelSchema describes the JSON fields of a model from its struct tags:
+// json for the name, binding for validation rules and gorm for fields the
+// database assigns. Fields named in readOnly are marked read-only as well.
+func modelSchema(model any, readOnly []string) []FieldSchema {
+	t := reflect.TypeOf(model)
+	var fields []FieldSchema
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := FieldSchema{Name: name, Type: schemaType(field.Type)}
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "":
+			case "required":
+				schema.Required = true
+			default:
+				if schema.Constraints == nil {
+					schema.Constraints = make(map[string]string)
+				}
+				schema.Constraints[key] = value
+			}
+		}
+		gormTag := field.Tag.Get("gorm")
+		schema.ReadOnly = strings.Contains(gormTag, "primaryKey") || slices.Contains(readOnly, name)
+		fields = append(fields, schema)
+	}
+	return fields
+}
+
+// @Summary Get the song schema
+// @Description Get the fields of a song with their types and validation rules, for building forms
+// @Produce json
+// @Success 200 {array} FieldSchema
+// @Router /songs/schema [get]
+func getSongSchema(c *gin.Context) {
+	fields := modelSchema(Song{}, serverManagedFields)
+	limits := songLengthLimits()
+	for i := range fields {
+		// Length limits are configured at runtime rather than in a tag.
+		if limit, ok := limits[fields[i].Name]; ok {
+			if fields[i].Constraints == nil {
+				fields[i].Constraints = make(map[string]string)
+			}
+			fields[i].Constraints["max"] = strconv.Itoa(limit)
+		}
+	}
+	respondJSON(c, http.StatusOK, fields)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSongSchema(t *testing.T) {
+	previous := cfg
+	cfg.MaxNameLength, cfg.MaxTextLength = 100, 5000
+	t.Cleanup(func() { cfg = previous })
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/songs/schema", nil)
+	getSongSchema(c)
+
+	var fields []FieldSchema
+	if err := json.Unmarshal(w.Body.Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]FieldSchema, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	tests := []struct {
+		name     string
+		readOnly bool
+		max      string
+	}{
+		{"id", true, ""},
+		{"uuid", true, ""},
+		{"group", false, "100"},
+		{"song", false, "100"},
+		{"text", false, "5000"},
+		{"link", false, ""},
+		{"links", true, ""},
+		{"play_count", true, ""},
+		{"flagged", true, ""},
+		{"link_status", true, ""},
+		{"link_checked_at", true, ""},
+		{"verse_count", true, ""},
+		{"word_count", true, ""},
+		{"line_count", true, ""},
+		{"created_at", true, ""},
+		{"updated_at", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, ok := byName[tt.name]
+			if !ok {
+				t.Fatalf("field %q missing from schema", tt.name)
+			}
+			if field.ReadOnly != tt.readOnly {
+				t.Errorf("read_only = %v, want %v", field.ReadOnly, tt.readOnly)
+			}
+			if got := field.Constraints["max"]; got != tt.max {
+				t.Errorf("max = %q, want %q", got, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// searchKeyBatchSize is how many songs backfillSearchKeys updates at a time.
+const searchKeyBatchSize = 500
+
+// searchKey folds s for case- and accent-insensitive lookups: accents are
+// stripped, letters lowercased and whitespace trimmed and collapsed, so
+// "  Beyoncé " and "beyonce" share a key.
+func searchKey(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// backfillSearchKeys computes the search keys of songs stored before they
+// existed.
+func backfillSearchKeys() {
+	var songs []Song
+	backfilled := 0
+	result := db.Select(`id, "group", song`).Where("group_key IS NULL OR song_key IS NULL").
+		FindInBatches(&songs, searchKeyBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, song := range songs {
+				if err := db.Model(&Song{}).Where("id = ?", song.ID).UpdateColumns(map[string]any{
+					"group_key": searchKey(song.Group),
+					"song_key":  searchKey(song.Song),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			backfilled += len(songs)
+			return nil
+		})
+	if result.Error != nil {
+		logrus.Errorf("Failed to backfill search keys: %v", result.Error)
+	} else if backfilled > 0 {
+		logrus.Infof("Backfilled search keys for %d songs", backfilled)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestSearchKey(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Beyoncé", "beyonce"},
+		{"  Beyoncé ", "beyonce"},
+		{"MOTÖRHEAD", "motorhead"},
+		{"Sigur  Rós", "sigur ros"},
+		{"Ça\tPlane Pour Moi", "ca plane pour moi"},
+		{"AC/DC", "ac/dc"},
+		{"Мельница", "мельница"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := searchKey(tt.in); got != tt.want {
+			t.Errorf("searchKey(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	frameOptionsDeny       = "DENY"
+	frameOptionsSameOrigin = "SAMEORIGIN"
+)
+
+// defaultContentSecurityPolicy suits responses that are only data: nothing
+// in them may load or run anything, or be framed.
+const defaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// swaggerContentSecurityPolicy lets the Swagger UI load its own assets and
+// run the inline script that configures it.
+const swaggerContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+// parsePrefixes parses a list of IP addresses and CIDR ranges as prefixes.
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or CIDR range %q", entry)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// fromTrustedProxy reports whether the request came through one of the
+// TRUSTED_PROXIES, whose X-Forwarded-Proto can be believed.
+func fromTrustedProxy(c *gin.Context) bool {
+	addr, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range cfg.TrustedProxyPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestScheme is the scheme the client used: the forwarded one behind a
+// trusted proxy, otherwise that of the connection.
+func requestScheme(c *gin.Context) string {
+	if fromTrustedProxy(c) {
+		if proto := strings.ToLower(c.GetHeader("X-Forwarded-Proto")); proto != "" {
+			return proto
+		}
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// securityHeaders sets the baseline security headers on every response and,
+// with HTTPS_REDIRECT, sends clients that reached a trusted proxy over plain
+// HTTP to the HTTPS address.
+func securityHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme := requestScheme(c)
+		if cfg.HTTPSRedirect && scheme == "http" && fromTrustedProxy(c) {
+			status := http.StatusPermanentRedirect
+			if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			c.Redirect(status, "https://"+c.Request.Host+c.Request.URL.RequestURI())
+			c.Abort()
+			return
+		}
+
+		if cfg.SecurityHeaders {
+			c.Header("X-Content-Type-Options", "nosniff")
+			c.Header("X-Frame-Options", cfg.FrameOptions)
+			policy := cfg.ContentSecurityPolicy
+			if strings.HasPrefix(c.Request.URL.Path, "/swagger/") {
+				policy = swaggerContentSecurityPolicy
+			}
+			c.Header("Content-Security-Policy", policy)
+		}
+		// Browsers ignore HSTS received over plain HTTP.
+		if cfg.HSTSMaxAge > 0 && scheme == "https" {
+			c.Header("Strict-Transport-Security", "max-age="+strconv.Itoa(cfg.HSTSMaxAge)+"; includeSubDomains")
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePrefixes(t *testing.T) {
+	got, err := parsePrefixes([]string{"10.0.0.1", "192.168.1.77/24", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.1/32"),
+		netip.MustParsePrefix("192.168.1.0/24"),
+		netip.MustParsePrefix("::1/128"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parsePrefixes() = %v, want %v", got, want)
+	}
+	if _, err := parsePrefixes([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("parsePrefixes() of an invalid range error = nil, want an error")
+	}
+}
+
+// withSecurityConfig sets the security options, trusting proxies in
+// 192.0.2.0/24, where httptest requests come from.
+func withSecurityConfig(t *testing.T, headers, httpsRedirect bool, hstsMaxAge int) {
+	t.Helper()
+	previous := cfg
+	cfg.SecurityHeaders, cfg.HTTPSRedirect, cfg.HSTSMaxAge = headers, httpsRedirect, hstsMaxAge
+	cfg.FrameOptions, cfg.ContentSecurityPolicy = frameOptionsDeny, defaultContentSecurityPolicy
+	cfg.TrustedProxyPrefixes = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+	t.Cleanup(func() { cfg = previous })
+}
+
+func securityRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(securityHeaders())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/songs", ok)
+	r.POST("/songs", ok)
+	r.GET("/swagger/*any", ok)
+	return r
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	withSecurityConfig(t, true, false, 0)
+	r := securityRouter()
+
+	w := serve(r, "/songs")
+	for header, want := range map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           frameOptionsDeny,
+		"Content-Security-Policy":   defaultContentSecurityPolicy,
+		"Strict-Transport-Security": "",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if got := serve(r, "/swagger/index.html").Header().Get("Content-Security-Policy"); got != swaggerContentSecurityPolicy {
+		t.Errorf("Content-Security-Policy of the Swagger UI = %q, want %q", got, swaggerContentSecurityPolicy)
+	}
+
+	withSecurityConfig(t, false, false, 0)
+	if got := serve(r, "/songs").Header().Get("X-Frame-Options"); got != "" {
+		t.Errorf("X-Frame-Options with the headers off = %q, want none", got)
+	}
+}
+
+func TestHTTPSRedirectAndHSTS(t *testing.T) {
+	withSecurityConfig(t, false, true, 31536000)
+	r := securityRouter()
+
+	tests := []struct {
+		name         string
+		method       string
+		remoteAddr   string
+		proto        string
+		wantStatus   int
+		wantLocation string
+		wantHSTS     bool
+	}{
+		{"plain http get", http.MethodGet, "192.0.2.10:4000", "http", http.StatusMovedPermanently, "https://example.com/songs?limit=5", false},
+		{"plain http post", http.MethodPost, "192.0.2.10:4000", "http", http.StatusPermanentRedirect, "https://example.com/songs?limit=5", false},
+		{"https through the proxy", http.MethodGet, "192.0.2.10:4000", "https", http.StatusOK, "", true},
+		{"untrusted client", http.MethodGet, "203.0.113.5:4000", "http", http.StatusOK, "", false},
+		{"forged proto", http.MethodGet, "203.0.113.5:4000", "https", http.StatusOK, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://example.com/songs?limit=5", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", tt.proto)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if got := w.Header().Get("Strict-Transport-Security") != ""; got != tt.wantHSTS {
+				t.Errorf("HSTS sent = %v, want %v", got, tt.wantHSTS)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// sampleSongs is the development data inserted by seedDB.
+var sampleSongs = []Song{
+	{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: "2006-07-16",
+		Text:        "Sample verse one, line one\nSample verse one, line two\n\nSample verse two, line one\nSample verse two, line two",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	},
+	{
+		Group:       "Muse",
+		Song:        "Hysteria",
+		ReleaseDate: "2003-12-01",
+		Text:        "Sample verse one, line one\nSample verse one, line two\n\nSample chorus, line one\nSample chorus, line two",
+		Link:        "https://www.youtube.com/watch?v=3dm_5qWWDV8",
+	},
+	{
+		Group:       "Radiohead",
+		Song:        "Karma Police",
+		ReleaseDate: "1997-08-25",
+		Text:        "Sample verse one, line one\nSample verse one, line two\n\nSample verse two, line one",
+		Link:        "https://www.youtube.com/watch?v=1uYWYWPc9HU",
+	},
+	{
+		Group:       "Queen",
+		Song:        "Bohemian Rhapsody",
+		ReleaseDate: "1975-10-31",
+		Text:        "Sample intro, line one\nSample intro, line two\n\nSample ballad, line one\nSample ballad, line two\n\nSample finale, line one",
+		Link:        "https://www.youtube.com/watch?v=fJ9rUzIMcZQ",
+	},
+}
+
+// seedDB inserts the sample songs when the songs table is empty. It never
+// runs in release mode.
+func seedDB() {
+	if gin.Mode() == gin.ReleaseMode {
+		logrus.Warn("Skipping database seeding in release mode")
+		return
+	}
+
+	var count int64
+	if err := db.Model(&Song{}).Count(&count).Error; err != nil {
+		logrus.Errorf("Failed to check songs before seeding: %v", err)
+		return
+	}
+	if count > 0 {
+		logrus.Info("Songs table is not empty, skipping seeding")
+		return
+	}
+
+	songs := make([]Song, len(sampleSongs))
+	copy(songs, sampleSongs)
+	if err := db.Create(&songs).Error; err != nil {
+		logrus.Errorf("Failed to seed songs: %v", err)
+		return
+	}
+	logrus.Infof("Seeded %d sample songs", len(songs))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSampleSongsPassSongChecks(t *testing.T) {
+	previous := cfg
+	cfg.MaxNameLength, cfg.MaxTextLength, cfg.Location = maxNameColumnSize, 10000, time.UTC
+	cfg.FutureReleaseDates = futureDatesStrict
+	t.Cleanup(func() { cfg = previous })
+
+	seen := make(map[string]bool)
+	for _, song := range sampleSongs {
+		if err := checkSong(&song, checkOptions{}); err != nil {
+			t.Errorf("sample song %q by %q: %v", song.Song, song.Group, err)
+		}
+		key := song.Group + "\x00" + song.Song
+		if seen[key] {
+			t.Errorf("sample song %q by %q appears twice", song.Song, song.Group)
+		}
+		seen[key] = true
+	}
+}
+
+func TestSeedDB(t *testing.T) {
+	testDB(t)
+	previousMode := gin.Mode()
+	t.Cleanup(func() { gin.SetMode(previousMode) })
+	count := func() int64 {
+		t.Helper()
+		var count int64
+		if err := db.Model(&Song{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	seedDB()
+	if n := count(); n != 0 {
+		t.Fatalf("seeded %d songs in release mode, want none", n)
+	}
+
+	gin.SetMode(gin.DebugMode)
+	seedDB()
+	seedDB()
+	if n := count(); n != int64(len(sampleSongs)) {
+		t.Errorf("songs after seeding twice = %d, want %d", n, len(sampleSongs))
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello", "hello"},
+		{"  Hello,   World! ", "hello world"},
+		{"Don't Stop Me Now", "dont stop me now"},
+		{"Song\t2\n(Remix)", "song 2 remix"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeTitle(tt.in); got != tt.want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Yesterday", "Yesterday", 1},
+		{"Yesterday", "yesterday!", 1},
+		{"", "", 1},
+		{"abcd", "abce", 0.75},
+		{"abc", "xyz", 0},
+		{"abc", "", 0},
+		{"Song 2", "Song", 4.0 / 6},
+	}
+	for _, tt := range tests {
+		got := similarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if back := similarity(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, not symmetric with %v", tt.b, tt.a, back, got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		query      string
+		want       []sortKey
+		wantStatus int
+	}{
+		{"", nil, 0},
+		{"sort=song", []sortKey{{column: "song"}}, 0},
+		{"sort=-release_date,group", []sortKey{{column: "release_date", desc: true}, {column: `"group"`}}, 0},
+		{"sort=song&locale=de", []sortKey{{column: `song COLLATE "de-x-icu"`}}, 0},
+		{"sort=-group&locale=sv_SE", []sortKey{{column: `"group" COLLATE "sv-x-icu"`, desc: true}}, 0},
+		{"sort=release_date&locale=de", []sortKey{{column: "release_date"}}, 0},
+		{"sort=text_length", []sortKey{{column: "length(text)"}}, 0},
+		{"sort=song&locale=tlh", nil, http.StatusBadRequest},
+		{"sort=lyrics", nil, http.StatusBadRequest},
+		{"sort=song%3BDROP%20TABLE%20songs", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs?"+tt.query, nil)
+			got, ok := parseSort(c)
+			if ok != (tt.wantStatus == 0) {
+				t.Fatalf("parseSort() ok = %v, status %d", ok, w.Code)
+			}
+			if !ok && w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSort() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortKeyOrder(t *testing.T) {
+	if got := (sortKey{column: "song"}).order(); got != "song ASC" {
+		t.Errorf("order() = %q, want song ASC", got)
+	}
+	if got := (sortKey{column: "play_count", desc: true}).order(); got != "play_count DESC" {
+		t.Errorf("order() = %q, want play_count DESC", got)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamHeartbeat is how often an idle change stream sends a comment, so
+	// proxies and clients don't take it for dead.
+	streamHeartbeat = 15 * time.Second
+	// streamBuffer is how many changes a stream client may lag behind before
+	// it is disconnected.
+	streamBuffer = 64
+)
+
+// SongChange is the data of a change stream event.
+type SongChange struct {
+	ID   SongRef `json:"id" swaggertype:"string"`
+	Type string  `json:"type"`
+}
+
+// changeStreams fans song changes out to the connected stream clients.
+var changeStreams = struct {
+	mu      sync.Mutex
+	clients map[chan SongChange]bool
+}{clients: make(map[chan SongChange]bool)}
+
+func init() {
+	subscribeSongChanges(func(song Song, changeType string) {
+		changeStreams.mu.Lock()
+		defer changeStreams.mu.Unlock()
+		for client := range changeStreams.clients {
+			select {
+			case client <- SongChange{ID: songRef(song), Type: changeType}:
+			default:
+				// Publishers mustn't block on a slow client. Dropping it makes
+				// it reconnect rather than silently miss changes.
+				delete(changeStreams.clients, client)
+				close(client)
+			}
+		}
+	})
+}
+
+func addStreamClient() chan SongChange {
+	client := make(chan SongChange, streamBuffer)
+	changeStreams.mu.Lock()
+	defer changeStreams.mu.Unlock()
+	changeStreams.clients[client] = true
+	return client
+}
+
+func removeStreamClient(client chan SongChange) {
+	changeStreams.mu.Lock()
+	defer changeStreams.mu.Unlock()
+	if changeStreams.clients[client] {
+		delete(changeStreams.clients, client)
+		close(client)
+	}
+}
+
+// @Summary Stream song changes
+// @Description Stream song changes as server-sent events named created, updated, regrouped or deleted, with the song ID and change type as JSON data. Idle streams get a comment every 15 seconds. Clients that fall too far behind are disconnected and should reconnect.
+// @Produce text/event-stream
+// @Success 200 {object} SongChange
+// @Router /songs/stream [get]
+func streamSongChanges(c *gin.Context) {
+	client := addStreamClient()
+	defer removeStreamClient(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteString(": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case change, ok := <-client:
+			if !ok {
+				return
+			}
+			c.SSEvent(change.Type, change)
+		case <-heartbeat.C:
+			c.Writer.WriteString(": heartbeat\n\n")
+		case <-c.Request.Context().Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamSongChangesSendsEvents(t *testing.T) {
+	withIDType(t, idTypeInt)
+	r := gin.New()
+	r.GET("/songs/stream", streamSongChanges)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/songs/stream", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() string {
+		t.Helper()
+		if !lines.Scan() {
+			t.Fatalf("stream ended: %v", lines.Err())
+		}
+		return lines.Text()
+	}
+	// The client is registered once the stream says it is connected.
+	if line := next(); line != ": connected" {
+		t.Fatalf("first line = %q, want the connected comment", line)
+	}
+	next()
+
+	onSongChanged(Song{ID: 7}, songRegrouped)
+	if line := next(); line != "event:"+songRegrouped {
+		t.Errorf("event line = %q, want %q", line, "event:"+songRegrouped)
+	}
+	if line, want := next(), `data:{"id":7,"type":"regrouped"}`; line != want {
+		t.Errorf("data line = %q, want %q", line, want)
+	}
+}
+
+func TestStreamDropsSlowClients(t *testing.T) {
+	client := addStreamClient()
+	defer removeStreamClient(client)
+
+	for i := range streamBuffer + 1 {
+		onSongChanged(Song{ID: uint(i + 1)}, songUpdated)
+	}
+	received := 0
+	for range client {
+		received++
+	}
+	if received != streamBuffer {
+		t.Errorf("received %d changes before being dropped, want %d", received, streamBuffer)
+	}
+}
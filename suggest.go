@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+const maxSuggestLimit = 20
+
+type Suggestion struct {
+	ID    uint   `json:"id"`
+	UUID  string `json:"uuid"`
+	Group string `json:"group"`
+	Song  string `json:"song"`
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// prefixPattern returns a LIKE pattern matching search keys that start with
+// prefix.
+func prefixPattern(prefix string) string {
+	return likeEscaper.Replace(searchKey(prefix)) + "%"
+}
+
+// @Summary Suggest songs for typeahead
+// @Description Get songs whose title or group starts with the query, best matches first
+// @Produce json
+// @Param q query string true "Prefix"
+// @Param limit query int false "Limit (default 5, max 20)"
+// @Success 200 {array} Suggestion
+// @Router /songs/suggest [get]
+func getSongSuggestions(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Missing q parameter")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit <= 0 {
+		limit = 5
+	}
+	limit = min(limit, maxSuggestLimit)
+
+	// Title matches rank above group matches, and shorter titles above
+	// longer ones since they are closer to what has been typed so far.
+	pattern := prefixPattern(q)
+	suggestions := []Suggestion{}
+	dbFrom(c).Model(&Song{}).
+		Select(`id, uuid, "group", song`).
+		Where(`song_key LIKE ? OR group_key LIKE ?`, pattern, pattern).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN song_key LIKE ? THEN 0 ELSE 1 END, length(song), id",
+			Vars:               []any{pattern},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Scan(&suggestions)
+
+	respondJSON(c, http.StatusOK, suggestions)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPrefixPattern(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"Bohemian", "bohemian%"},
+		{"  Beyoncé  Knowles", "beyonce knowles%"},
+		{"100%", `100\%%`},
+		{"a_b", `a\_b%`},
+		{`back\slash`, `back\\slash%`},
+	}
+	for _, tt := range tests {
+		if got := prefixPattern(tt.prefix); got != tt.want {
+			t.Errorf("prefixPattern(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestGetSongSuggestions(t *testing.T) {
+	testDB(t)
+	for _, song := range []Song{
+		{Group: "Queen", Song: "Bohemian Rhapsody"},
+		{Group: "Bohemian Betyars", Song: "Dance"},
+		{Group: "Muse", Song: "Bohemia"},
+		{Group: "Muse", Song: "Uprising"},
+	} {
+		if err := db.Create(&song).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/songs/suggest", getSongSuggestions)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/suggest?q=bohem", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var suggestions []Suggestion
+	if err := json.Unmarshal(w.Body.Bytes(), &suggestions); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, suggestion := range suggestions {
+		got = append(got, suggestion.Song)
+	}
+	// Titles first, shortest first, then group matches.
+	if want := []string{"Bohemia", "Bohemian Rhapsody", "Dance"}; !slices.Equal(got, want) {
+		t.Errorf("suggestions = %v, want %v", got, want)
+	}
+}
+
+func TestGetSongSuggestionsRequiresQuery(t *testing.T) {
+	r := gin.New()
+	r.GET("/songs/suggest", getSongSuggestions)
+	if code := serve(r, "/songs/suggest?q=%20").Code; code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+	keycapCombining = '\u20e3'
+)
+
+// extendsPrevious reports whether r only makes sense attached to the rune
+// before it: combining marks, variation selectors, emoji skin tones and tag
+// characters, and the zero width joiner used in emoji sequences.
+func extendsPrevious(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner, r == keycapCombining:
+		return true
+	case r >= '\ufe00' && r <= '\ufe0f':
+		return true
+	case r >= '\U0001f3fb' && r <= '\U0001f3ff':
+		return true
+	case r >= '\U000e0020' && r <= '\U000e007f':
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= '\U0001f1e6' && r <= '\U0001f1ff'
+}
+
+// truncateRunes returns at most n runes of s without splitting a character
+// that is made of several runes, such as an accented letter written with a
+// combining mark, a flag or a ZWJ emoji sequence. The result may be shorter
+// than n runes so that such a character is dropped whole.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	cut := max(n, 0)
+	for cut > 0 && (extendsPrevious(runes[cut]) || runes[cut-1] == zeroWidthJoiner) {
+		cut--
+	}
+	// Regional indicators pair up into flags; don't keep an odd one out.
+	indicators := 0
+	for i := cut - 1; i >= 0 && isRegionalIndicator(runes[i]); i-- {
+		indicators++
+	}
+	if indicators%2 == 1 && isRegionalIndicator(runes[cut]) {
+		cut--
+	}
+	return string(runes[:cut])
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short enough", "hello", 10, "hello"},
+		{"ascii", "hello", 3, "hel"},
+		{"zero", "hello", 0, ""},
+		{"negative", "hello", -1, ""},
+		{"precomposed accent", "café!", 4, "café"},
+		{"combining accent", "cafe\u0301!", 4, "caf"},
+		{"combining accent kept whole", "cafe\u0301!", 5, "cafe\u0301"},
+		{"emoji", "ok 🎸🎸", 4, "ok 🎸"},
+		{"skin tone", "a\U0001F44D\U0001F3FD", 2, "a"},
+		{"zwj sequence", "a\U0001F468\u200d\U0001F469\u200d\U0001F467", 4, "a"},
+		{"flags", "🇩🇪🇫🇷", 3, "🇩🇪"},
+		{"flags whole", "🇩🇪🇫🇷", 4, "🇩🇪🇫🇷"},
+		{"keycap", "a1\ufe0f\u20e3", 3, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateRunes(tt.s, tt.n); got != tt.want {
+				t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+		})
+	}
+}
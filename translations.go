@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SongLyrics is the text of a song in one language. The row for
+// cfg.DefaultLyricsLang mirrors Song.Text and is kept in sync by Song.AfterSave.
+type SongLyrics struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	SongID    uint      `json:"song_id" gorm:"uniqueIndex:idx_song_lyrics_lang;not null"`
+	Lang      string    `json:"lang" gorm:"uniqueIndex:idx_song_lyrics_lang;not null"`
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var langTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// normalizeLang lowercases a BCP 47 language tag and reports whether it is
+// well-formed.
+func normalizeLang(lang string) (string, bool) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	return lang, langTagPattern.MatchString(lang)
+}
+
+func saveLyrics(tx *gorm.DB, lyrics *SongLyrics) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "song_id"}, {Name: "lang"}},
+		DoUpdates: clause.AssignmentColumns([]string{"text", "updated_at"}),
+	}).Create(lyrics).Error
+}
+
+// AfterSave copies the primary lyrics into the default-language row.
+func (s *Song) AfterSave(tx *gorm.DB) error {
+	if s.ID == 0 {
+		return nil
+	}
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	if strings.TrimSpace(s.Text) == "" {
+		return tx.Where("song_id = ? AND lang = ?", s.ID, cfg.DefaultLyricsLang).Delete(&SongLyrics{}).Error
+	}
+	return saveLyrics(tx, &SongLyrics{SongID: s.ID, Lang: cfg.DefaultLyricsLang, Text: s.Text})
+}
+
+// backfillSongLyrics copies the text of songs that predate SongLyrics into
+// default-language rows.
+func backfillSongLyrics() {
+	result := db.Exec(`INSERT INTO song_lyrics (song_id, lang, text, updated_at)
+		SELECT id, ?, text, updated_at FROM songs
+		WHERE trim(text) <> '' AND NOT EXISTS (
+			SELECT 1 FROM song_lyrics WHERE song_lyrics.song_id = songs.id AND song_lyrics.lang = ?)`,
+		cfg.DefaultLyricsLang, cfg.DefaultLyricsLang)
+	if result.Error != nil {
+		logrus.Errorf("Failed to backfill song lyrics: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		logrus.Infof("Backfilled lyrics for %d songs", result.RowsAffected)
+	}
+}
+
+// lyricsText returns the text of song in lang, which defaults to the
+// primary lyrics stored on the song.
+func lyricsText(c *gin.Context, song Song, lang string) (string, bool) {
+	if lang == "" {
+		return song.Text, true
+	}
+	lang, ok := normalizeLang(lang)
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid lang parameter, expected a language tag like en or pt-br")
+		return "", false
+	}
+	if lang == cfg.DefaultLyricsLang {
+		return song.Text, true
+	}
+	var lyrics SongLyrics
+	if err := dbFrom(c).Where("song_id = ? AND lang = ?", song.ID, lang).Take(&lyrics).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, codeNotFound, "No lyrics in language "+lang)
+		} else {
+			respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get lyrics")
+		}
+		return "", false
+	}
+	return lyrics.Text, true
+}
+
+type TranslationRequest struct {
+	Lang string `json:"lang" binding:"required"`
+	Text string `json:"text" binding:"required"`
+}
+
+// @Summary Add a lyrics translation
+// @Description Add or replace the lyrics of a song in another language. The default language mirrors the song text and can't be set here.
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Param translation body TranslationRequest true "Language and lyrics"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
+// @Success 201 {object} SongLyrics
+// @Failure 400 {object} APIError
+// @Router /songs/{id}/translations [post]
+func addSongTranslation(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+
+	var req TranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	lang, ok := normalizeLang(req.Lang)
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Invalid lang, expected a language tag like en or pt-br")
+		return
+	}
+	if lang == cfg.DefaultLyricsLang {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, "Lyrics in the default language are edited through the song text")
+		return
+	}
+	text, ok := limitText(req.Text, c.Query("truncate") == "true")
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeInvalidInput, fmt.Sprintf("Text exceeds maximum length of %d characters", cfg.MaxTextLength))
+		return
+	}
+
+	lyrics := SongLyrics{SongID: song.ID, Lang: lang, Text: text}
+	if err := saveLyrics(dbFrom(c), &lyrics); err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to save translation")
+		return
+	}
+	respondJSON(c, http.StatusCreated, lyrics)
+}
+
+// @Summary List lyrics languages
+// @Description Get the languages a song has lyrics in, the default language included
+// @Produce json
+// @Param id path string true "Song ID, or UUID with ID_TYPE=uuid"
+// @Success 200 {array} string
+// @Router /songs/{id}/translations [get]
+func getSongTranslations(c *gin.Context) {
+	var song Song
+	if !findSong(c, dbFrom(c), &song) {
+		return
+	}
+	var langs []string
+	if err := dbFrom(c).Model(&SongLyrics{}).Where("song_id = ?", song.ID).Order("lang").Pluck("lang", &langs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to get translations")
+		return
+	}
+	respondJSON(c, http.StatusOK, langs)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNormalizeLang(t *testing.T) {
+	tests := []struct {
+		lang   string
+		want   string
+		wantOK bool
+	}{
+		{"en", "en", true},
+		{" PT-BR ", "pt-br", true},
+		{"und", "und", true},
+		{"zh-hant-tw", "zh-hant-tw", true},
+		{"", "", false},
+		{"e", "e", false},
+		{"english", "english", false},
+		{"en_us", "en_us", false},
+		{"en-", "en-", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeLang(tt.lang)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("normalizeLang(%q) = %q, %v, want %q, %v", tt.lang, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAddSongTranslation(t *testing.T) {
+	testDB(t)
+	song := Song{Group: "Queen", Song: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	if err := db.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.GET("/songs/:id/lyrics", getSongLyrics)
+	r.GET("/songs/:id/translations", getSongTranslations)
+	r.POST("/songs/:id/translations", addSongTranslation)
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/songs/1/translations", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := post(`{"lang":"PT-BR","text":"Isso é a vida real?"}`); code != http.StatusCreated {
+		t.Fatalf("add status = %d, want %d", code, http.StatusCreated)
+	}
+	if code := post(`{"lang":"pt_br","text":"x"}`); code != http.StatusBadRequest {
+		t.Errorf("invalid lang status = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code := post(`{"lang":"` + cfg.DefaultLyricsLang + `","text":"x"}`); code != http.StatusBadRequest {
+		t.Errorf("default lang status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	w := serve(r, "/songs/1/translations")
+	var langs []string
+	if err := json.Unmarshal(w.Body.Bytes(), &langs); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"pt-br", cfg.DefaultLyricsLang}; !reflect.DeepEqual(langs, want) {
+		t.Errorf("translations = %q, want %q", langs, want)
+	}
+
+	w = serve(r, "/songs/1/lyrics?lang=pt-br")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Isso é a vida real?") {
+		t.Errorf("lyrics?lang=pt-br = %d %s, want the translation", w.Code, w.Body)
+	}
+	if w = serve(r, "/songs/1/lyrics?lang=fr"); w.Code != http.StatusNotFound {
+		t.Errorf("lyrics?lang=fr status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// findExistingSong loads the first song with the group and title of song.
+func findExistingSong(tx *gorm.DB, song Song, existing *Song) (bool, error) {
+	err := tx.Preload("Links").Where(`"group" = ? AND song = ?`, song.Group, song.Song).Order("id").Take(existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// @Summary Create a song unless it exists
+// @Description Return the song with the group and title of the body, creating it from the body if there is none yet. Concurrent calls for the same song create it once.
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "Locale used to format release dates, in which they are also accepted"
+// @Param song body Song true "Song Data"
+// @Param truncate query bool false "Truncate text exceeding the maximum length instead of rejecting it"
+// @Success 200 {object} Song "The song already existed"
+// @Success 201 {object} Song "The song was created"
+// @Failure 409 {object} APIError "A unique field is taken by another song"
+// @Failure 415 {object} APIError
+// @Router /songs [put]
+func createSongIfNotExists(c *gin.Context) {
+	var song Song
+	if err := bindSong(c, &song); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var existing Song
+	found, err := findExistingSong(dbFrom(c), song, &existing)
+	if err == nil && !found {
+		song.ID = 0
+		enrichSong(c.Request.Context(), &song)
+		if !validateSong(c, &song) {
+			return
+		}
+		err = dbFrom(c).Transaction(func(tx *gorm.DB) error {
+			// Callers creating the same song are serialized by a lock on the
+			// pair, so they get the created song rather than a unique
+			// violation from idx_songs_group_song.
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), hashtext(?))", song.Group, song.Song).Error; err != nil {
+				return err
+			}
+			if found, err = findExistingSong(tx, song, &existing); err != nil || found {
+				return err
+			}
+			if err := tx.Omit(clause.Associations).Create(&song).Error; err != nil {
+				return err
+			}
+			return setPrimaryLink(tx, &song, song.Link)
+		})
+	}
+	if conflict, ok := asConflict(err); ok {
+		respondConflict(c, conflict)
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, codeInternalError, "Failed to create song")
+		return
+	}
+
+	if !found {
+		onSongChanged(song, songCreated)
+		respondCreatedSong(c, song)
+		return
+	}
+	localizeSong(c, &existing)
+	respondJSON(c, http.StatusOK, existing)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateSongIfNotExistsConcurrent(t *testing.T) {
+	testDB(t)
+	r := gin.New()
+	r.PUT("/songs", createSongIfNotExists)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	statuses := map[int]int{}
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/songs", strings.NewReader(`{"group": "Muse", "song": "Uprising"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			mu.Lock()
+			statuses[w.Code]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if statuses[http.StatusCreated] != 1 || statuses[http.StatusOK] != callers-1 {
+		t.Errorf("statuses = %v, want one %d and %d times %d", statuses, http.StatusCreated, callers-1, http.StatusOK)
+	}
+	var count int64
+	if err := db.Model(&Song{}).Where(`"group" = ? AND song = ?`, "Muse", "Uprising").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("stored %d songs, want 1", count)
+	}
+}